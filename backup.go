@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"fancyCards/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ******************************************************
+// VIEWS
+// ******************************************************
+
+func (a *WordCardsApp) BackupDialog() {
+	withDecks := widget.NewCheck(core.T("backup.withDecks"), nil)
+	dialog.ShowCustomConfirm(core.T("backup.create"), core.T("button.continue"), core.T("button.cancel"), withDecks, func(ok bool) {
+		if !ok {
+			return
+		}
+		a.ShowSaveError(a.SaveAll())
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, a.window)
+				return
+			}
+			if writer == nil {
+				return // cancelled
+			}
+			defer writer.Close()
+			if _, err := core.WriteBackup(writer, a.conf, a.profile.Name, withDecks.Checked); err != nil {
+				dialog.ShowError(err, a.window)
+			}
+		}, a.window)
+		saveDialog.SetFileName(fmt.Sprintf("fancyCards-%s-%s.zip", a.profile.Name, time.Now().Format("2006-01-02")))
+		saveDialog.Show()
+	}, a.window)
+}
+
+func (a *WordCardsApp) RestoreDialog() {
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if reader == nil {
+			return // cancelled
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		m, files, err := core.ReadBackup(data)
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
+		msg := core.T("backup.restoreConfirm", "Created", m.Created.Format(core.T("format.dateTime")),
+			"Plan", strings.Join(core.RestorePlan(a.conf, m), "\n- "))
+		dialog.ShowConfirm(core.T("backup.restore"), msg, func(ok bool) {
+			if !ok {
+				return
+			}
+			active := m.Profile == a.profile.Name
+			if active {
+				a.ShowSaveError(a.CloseProfile())
+			}
+			err := core.RestoreBackup(a.conf, m, files)
+			a.HandleError(a.InitializeCardIndex())
+			if active || a.profile.Name == "" {
+				a.SelectProfile(m.Profile)
+			} else {
+				a.ShowProfiles()
+			}
+			if err != nil {
+				dialog.ShowError(err, a.window)
+			} else if m.WithDecks {
+				dialog.ShowInformation(core.T("backup.restored"), core.T("backup.restart"), a.window)
+			}
+		}, a.window)
+	}, a.window)
+}
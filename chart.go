@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+type ChartKind int
+
+const (
+	BarChart ChartKind = iota
+	LineChart
+)
+
+// Chart holds one value per period. It can be drawn with fyne canvas objects
+// or rendered into an image, both based on the same geometry.
+type Chart struct {
+	title    string
+	kind     ChartKind
+	labels   []string
+	values   []float64
+	maxValue float64
+	unit     string
+}
+
+type chartBox struct {
+	x0, y0, x1, y1 float32 // y0 is the top of the bar/the point of the line
+}
+
+var (
+	chartColor      = color.NRGBA{R: 0x30, G: 0xa1, B: 0x4e, A: 0xff}
+	chartAxisColor  = color.NRGBA{R: 0x99, G: 0x99, B: 0x99, A: 0xff}
+	chartTextColor  = color.NRGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}
+	chartBackground = color.White
+)
+
+const (
+	chartWidth   = 600
+	chartHeight  = 180
+	chartPadding = 20 // room for the title above and the labels below the plot
+)
+
+func NewChart(title string, kind ChartKind, labels []string, values []float64, unit string) Chart {
+	c := Chart{title: title, kind: kind, labels: labels, values: values, unit: unit}
+	for _, v := range values {
+		c.maxValue = max(c.maxValue, v)
+	}
+	if c.maxValue == 0 {
+		c.maxValue = 1
+	}
+	return c
+}
+
+func (c Chart) geometry(width float32, height float32) []chartBox {
+	boxes := []chartBox{}
+	if len(c.values) == 0 {
+		return boxes
+	}
+	plotHeight := height - 2*chartPadding
+	slot := width / float32(len(c.values))
+	for i, v := range c.values {
+		x0 := float32(i)*slot + slot*0.1
+		x1 := float32(i+1)*slot - slot*0.1
+		y1 := height - chartPadding
+		y0 := y1 - plotHeight*float32(v/c.maxValue)
+		boxes = append(boxes, chartBox{x0: x0, y0: y0, x1: x1, y1: y1})
+	}
+	return boxes
+}
+
+func (c Chart) axisLabels() (string, string, string) {
+	first, last := "", ""
+	if len(c.labels) > 0 {
+		first = c.labels[0]
+		last = c.labels[len(c.labels)-1]
+	}
+	return fmt.Sprintf("%s (max. %.0f%s)", c.title, c.maxValue, c.unit), first, last
+}
+
+// ******************************************************
+// FYNE
+// ******************************************************
+
+func (c Chart) Render() fyne.CanvasObject {
+	w, h := float32(chartWidth), float32(chartHeight)
+	objects := []fyne.CanvasObject{}
+
+	background := canvas.NewRectangle(chartBackground)
+	background.Resize(fyne.NewSize(w, h))
+	objects = append(objects, background)
+
+	axis := canvas.NewLine(chartAxisColor)
+	axis.Position1 = fyne.NewPos(0, h-chartPadding)
+	axis.Position2 = fyne.NewPos(w, h-chartPadding)
+	objects = append(objects, axis)
+
+	boxes := c.geometry(w, h)
+	for i, b := range boxes {
+		switch c.kind {
+		case BarChart:
+			bar := canvas.NewRectangle(chartColor)
+			bar.Move(fyne.NewPos(b.x0, b.y0))
+			bar.Resize(fyne.NewSize(b.x1-b.x0, b.y1-b.y0))
+			objects = append(objects, bar)
+		case LineChart:
+			if i == 0 {
+				continue
+			}
+			prev := boxes[i-1]
+			line := canvas.NewLine(chartColor)
+			line.StrokeWidth = 2
+			line.Position1 = fyne.NewPos((prev.x0+prev.x1)/2, prev.y0)
+			line.Position2 = fyne.NewPos((b.x0+b.x1)/2, b.y0)
+			objects = append(objects, line)
+		}
+	}
+
+	title, first, last := c.axisLabels()
+	objects = append(objects, chartText(title, fyne.NewPos(0, 0)))
+	objects = append(objects, chartText(first, fyne.NewPos(0, h-chartPadding+2)))
+	lastText := chartText(last, fyne.NewPos(0, h-chartPadding+2))
+	lastText.Move(fyne.NewPos(w-lastText.MinSize().Width, h-chartPadding+2))
+	objects = append(objects, lastText)
+
+	plot := container.NewWithoutLayout(objects...)
+	sized := canvas.NewRectangle(color.Transparent)
+	sized.SetMinSize(fyne.NewSize(w, h))
+	return container.NewStack(sized, plot)
+}
+
+func chartText(str string, pos fyne.Position) *canvas.Text {
+	t := canvas.NewText(str, chartTextColor)
+	t.TextSize = 11
+	t.Move(pos)
+	return t
+}
+
+// ******************************************************
+// PNG EXPORT
+// ******************************************************
+
+func (c Chart) Image() image.Image {
+	w, h := chartWidth, chartHeight
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(chartBackground), image.Point{}, draw.Src)
+	fillRect(img, 0, h-chartPadding, w, h-chartPadding+1, chartAxisColor)
+
+	boxes := c.geometry(float32(w), float32(h))
+	for i, b := range boxes {
+		switch c.kind {
+		case BarChart:
+			fillRect(img, int(b.x0), int(b.y0), int(b.x1), int(b.y1), chartColor)
+		case LineChart:
+			if i == 0 {
+				continue
+			}
+			prev := boxes[i-1]
+			drawLine(img, (prev.x0+prev.x1)/2, prev.y0, (b.x0+b.x1)/2, b.y0, chartColor)
+		}
+	}
+
+	title, first, last := c.axisLabels()
+	drawText(img, title, 2, 13)
+	drawText(img, first, 2, h-4)
+	drawText(img, last, w-2-7*len([]rune(last)), h-4)
+	return img
+}
+
+func (c Chart) WritePNG(w io.Writer) error {
+	return png.Encode(w, c.Image())
+}
+
+func fillRect(img *image.RGBA, x0, y0, x1, y1 int, col color.Color) {
+	draw.Draw(img, image.Rect(x0, y0, x1, y1), image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float32, col color.Color) {
+	steps := int(max(abs32(x1-x0), abs32(y1-y0))) + 1
+	for i := 0; i <= steps; i++ {
+		t := float32(i) / float32(steps)
+		x := int(x0 + t*(x1-x0))
+		y := int(y0 + t*(y1-y0))
+		fillRect(img, x-1, y-1, x+1, y+1, col)
+	}
+}
+
+func abs32(f float32) float32 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+func drawText(img *image.RGBA, str string, x, y int) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(chartTextColor),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(str)
+}
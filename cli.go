@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"fancyCards/core"
+)
+
+// Commands run without the GUI, e.g. "fancyCards practice de_fr -groups Gruppe1".
+// Without a command the GUI is started.
+var commands = map[string]func(args []string) error{
+	"practice": RunPractice,
+	"validate": RunValidate,
+	"stats":    RunStats,
+	"serve":    RunServe,
+	"print":    RunPrint,
+}
+
+// parseInterspersed allows flags after the positional arguments,
+// which the flag package stops at
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return positional, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// loadConfig loads the INI for a command, all errors are fatal there. Unlike the GUI,
+// commands never create a default INI: a mistyped -config fails instead of giving empty results.
+func loadConfig(opts Options) (core.Config, error) {
+	inipath, ok := findConfigFile(opts)
+	if !ok {
+		return core.Config{}, core.NewError("config.notFound", "File", inipath)
+	}
+	conf, errorList := core.LoadConfig(inipath)
+	if len(errorList) > 0 {
+		return conf, errors.Join(errorList...)
+	}
+	applyOptions(&conf, opts)
+	return conf, nil
+}
+
+// openCommandProfile loads the profile for a command. Problems which don't stop
+// the command, e.g. statistics recovered from a backup, are printed as warnings.
+func openCommandProfile(conf core.Config, name string) (*core.Profile, error) {
+	if conf.SavDir == "" {
+		fmt.Fprintln(os.Stderr, core.T("cli.note"), core.T("cli.notSaved"))
+		return core.InMemoryProfile(), nil
+	}
+	name, err := core.StartupProfile(conf.SavDir, name)
+	if err != nil {
+		return nil, err
+	}
+	profile, errorList := core.OpenProfile(conf.SavDir, name, conf.Storage)
+	for _, err := range errorList {
+		fmt.Fprintln(os.Stderr, core.T("cli.note"), err)
+	}
+	if err := profile.AcquireLock(); err != nil {
+		fmt.Fprintln(os.Stderr, core.T("cli.note"), err)
+	}
+	return profile, nil
+}
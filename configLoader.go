@@ -1,245 +0,0 @@
-package main
-
-import (
-	"encoding/csv"
-	"fmt"
-	"os"
-	"slices"
-	"strings"
-
-	ini "gopkg.in/ini.v1"
-)
-
-var defaultIni string = `[LANGUAGES]
-de=Deutsch
-pl=Polnisch
-en=Englisch
-fr=Französisch
-es=Spanisch
-it=Italienisch
-pt=Portugiesisch
-nl=Niederländisch
-hr=Kroatisch
-
-[CONFIGFILES]
-fileListConfigFile=config/dateien.csv
-inputDirPrefix=input_
-savDir=sav
-`
-
-type InputFile struct {
-	fileName         string
-	groups           []string
-	sourceWordCol    int
-	targetWordCol    int
-	sourceCommentCol int
-	targetCommentCol int
-	skipHeaderLine   bool
-}
-
-func defaultInputFile() InputFile {
-	k := InputFile{
-		sourceWordCol:    0,
-		targetWordCol:    1,
-		sourceCommentCol: 2,
-		skipHeaderLine:   true,
-		targetCommentCol: 3,
-	}
-	return k
-}
-
-type LangPair struct {
-	sourceLang string
-	targetLang string
-}
-
-func (l LangPair) ToString() string {
-	return l.sourceLang + "_" + l.targetLang
-}
-
-func (l LangPair) Flip() LangPair {
-	return LangPair{sourceLang: l.targetLang, targetLang: l.sourceLang}
-}
-
-type CardsConfig struct {
-	languageNames      map[string]string
-	langPairs          []LangPair
-	savDir             string
-	inputDirPrefix     string
-	fileListConfigFile string
-	files              map[string][]InputFile
-}
-
-func (c CardsConfig) Init() CardsConfig {
-	c.languageNames = map[string]string{}
-	c.inputDirPrefix = ""
-	c.fileListConfigFile = ""
-	c.langPairs = []LangPair{}
-	c.files = map[string][]InputFile{}
-	return c
-}
-
-func (c *CardsConfig) AddFile(fn string, lp LangPair) {
-	km := defaultInputFile()
-	km.fileName = fn
-	c.files[lp.ToString()] = append(c.files[lp.ToString()], km)
-}
-
-func (c *CardsConfig) ValidateAndAddFile(file InputFile, lp LangPair, lineNo int) error {
-	if c.GetLangName(lp.sourceLang) == "" {
-		return fmt.Errorf("%s - Ungültige Ausgangssprache '%s' in Zeile %d", c.fileListConfigFile, lp.sourceLang, lineNo+1)
-	} else if c.GetLangName(lp.targetLang) == "" {
-		return fmt.Errorf("%s - Ungültige Lernsprache '%s' in Zeile %d", c.fileListConfigFile, lp.sourceLang, lineNo+1)
-	}
-
-	c.files[lp.ToString()] = append(c.files[lp.ToString()], file)
-	if !c.LangPairExists(lp) {
-		c.langPairs = append(c.langPairs, lp)
-	}
-	return nil
-}
-
-func (c CardsConfig) GetInputFiles(lpst string) []InputFile {
-	return c.files[lpst]
-}
-
-func (c CardsConfig) GetGroups(lp LangPair) []string {
-	groupList := make([]string, 0)
-	for _, file := range c.GetInputFiles(lp.ToString()) {
-		for _, group := range file.groups {
-			if !slices.Contains(groupList, group) {
-				groupList = append(groupList, group)
-			}
-		}
-	}
-	return groupList
-}
-
-func (c CardsConfig) GetLangName(id string) string {
-	return string(c.languageNames[id])
-}
-
-func (c CardsConfig) GetLangPairAsString(lp LangPair) string {
-	sl := c.GetLangName(lp.sourceLang)
-	tl := c.GetLangName(lp.targetLang)
-	lpstr := fmt.Sprintf("%s -> %s", sl, tl)
-	return lpstr
-}
-
-func (c CardsConfig) LangPairExists(lp LangPair) bool {
-	for _, existingLP := range c.langPairs {
-		if lp.ToString() == existingLP.ToString() {
-			return true
-		}
-	}
-	return false
-}
-
-func processLanguageFileLine(input []string, i int) (InputFile, LangPair, error) {
-	f := defaultInputFile()
-	f.groups = make([]string, 0)
-	lp := LangPair{}
-	var err error
-
-	if len(input) < 3 {
-		err = fmt.Errorf("Zeile %d ist zu kurz!\n", i+1)
-	} else if input[0] == "" || input[1] == "" || input[2] == "" {
-		err = fmt.Errorf("Zeile %d ist ungültig - in Spalten 1 bis 3 dürfen keine leeren Einträge sein!\n", i+1)
-	} else {
-		lp.sourceLang = input[0]
-		lp.targetLang = input[1]
-		f.fileName = input[2]
-
-		if len(input) >= 4 {
-			groupsRaw := strings.Split(input[3], ",")
-			for _, str := range groupsRaw {
-				if strings.Trim(str, " ") != "" {
-					f.groups = append(f.groups, strings.Trim(str, " "))
-				}
-			}
-		}
-	}
-	return f, lp, err
-}
-
-func (c *CardsConfig) ReadLanguagesFile() []error {
-	errorList := []error{}
-	f, err := os.Open(c.fileListConfigFile)
-	if err != nil {
-		return []error{err}
-	}
-	// Lese Sprachen aus CSV-Datei ein
-	reader := csv.NewReader(f)
-	reader.Comma = ';'
-
-	inputData, err := reader.ReadAll()
-	if err != nil {
-		return []error{err}
-	}
-	for i, ds := range inputData {
-		if i == 0 {
-			continue
-		}
-		file, lp, err := processLanguageFileLine(ds, i)
-		if err != nil {
-			errorList = append(errorList, err)
-			continue
-		}
-		err = c.ValidateAndAddFile(file, lp, i)
-		if err != nil {
-			errorList = append(errorList, err)
-		}
-
-	}
-
-	return errorList
-}
-
-func loadConfigsIni(inipath string) (CardsConfig, []error) {
-	c := CardsConfig{}.Init()
-	errorList := []error{}
-	CardsIniReader, err := ini.Load(inipath)
-	if err != nil {
-		return c, []error{err}
-	}
-
-	langSection := CardsIniReader.Section("LANGUAGES")
-	for _, k := range langSection.Keys() {
-		langKey := k.Name()
-		c.languageNames[langKey] = k.String()
-	}
-
-	configfilesSection := CardsIniReader.Section("CONFIGFILES")
-	for _, k := range configfilesSection.Keys() {
-		val := k.String()
-		switch k.Name() {
-		case "inputDirPrefix":
-			c.inputDirPrefix = val
-		case "fileListConfigFile":
-			c.fileListConfigFile = val
-		case "savDir":
-			c.savDir = val
-		}
-	}
-
-	if len(configfilesSection.Keys()) == 0 {
-		errorList = append(errorList, fmt.Errorf("Fehler beim Einlesen der %s: Bereich [CONFIGFILES] fehlt", inipath))
-	}
-	if c.inputDirPrefix == "" || c.fileListConfigFile == "" {
-		errorList = append(errorList, fmt.Errorf("Fehler beim Einlesen der %s:\n Im Bereich [CONFIGFILES] fehlt inputDirPrefix und/oder fileListConfigFile", inipath))
-	}
-	if len(c.languageNames) == 0 {
-		errorList = append(errorList, fmt.Errorf("Fehler beim Einlesen der %s: Keine Sprachen definiert", inipath))
-	}
-
-	if len(errorList) == 0 {
-		errorList = c.ReadLanguagesFile()
-	}
-
-	return c, errorList
-}
-
-func CreateDefaultIni(inipath string) {
-	data := []byte(defaultIni)
-	os.WriteFile(inipath, data, 0644)
-}
@@ -0,0 +1,357 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// A backup is a zip file with the directory of one profile, the card index and
+// optionally the decks. manifest.json lists every file with its checksum, so that
+// a damaged or foreign archive is rejected before anything is replaced:
+//
+//	manifest.json
+//	profil/...            everything in savDir/profile/<name>
+//	karten.json           the card index of savDir
+//	decks/dateien.csv     the file list
+//	decks/<pair>/<file>   the CSV files of each language pair
+
+const (
+	backupApp       = "fancyCards"
+	backupVersion   = 1
+	backupManifest  = "manifest.json"
+	backupProfile   = "profil/"
+	backupCardIndex = "karten.json"
+	backupDecks     = "decks/"
+	backupFileList  = backupDecks + "dateien.csv"
+)
+
+type BackupFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+type BackupManifest struct {
+	App       string       `json:"app"`
+	Version   int          `json:"version"`
+	Created   time.Time    `json:"created"`
+	Profile   string       `json:"profile"`
+	Storage   string       `json:"storage"`
+	WithDecks bool         `json:"withDecks"`
+	Files     []BackupFile `json:"files"`
+}
+
+func (m BackupManifest) Contains(prefix string) bool {
+	for _, f := range m.Files {
+		if strings.HasPrefix(f.Name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ******************************************************
+// WRITING
+// ******************************************************
+
+// skipInBackup leaves out locks and leftovers of interrupted saves
+func skipInBackup(name string) bool {
+	return name == lockFileName || name == saveLockFileName || strings.Contains(name, ".tmp")
+}
+
+// deckFiles maps the names in the archive to the deck files on disk
+func deckFiles(conf Config) map[string]string {
+	files := map[string]string{backupFileList: conf.FileListConfigFile}
+	for _, lp := range conf.LangPairs {
+		for _, f := range conf.GetInputFiles(lp.ToString()) {
+			files[backupDecks+lp.ToString()+"/"+f.FileName] = filepath.Join(conf.InputDirPrefix+lp.ToString(), f.FileName)
+		}
+	}
+	return files
+}
+
+func WriteBackup(w io.Writer, conf Config, profile string, withDecks bool) (BackupManifest, error) {
+	m := BackupManifest{App: backupApp, Version: backupVersion, Created: time.Now(), Profile: profile, Storage: conf.Storage, WithDecks: withDecks}
+	files := map[string]string{}
+
+	dataDir := ProfileDir(conf.SavDir, profile)
+	err := filepath.WalkDir(dataDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || skipInBackup(d.Name()) {
+			return err
+		}
+		rel, err := filepath.Rel(dataDir, p)
+		if err != nil {
+			return err
+		}
+		files[backupProfile+filepath.ToSlash(rel)] = p
+		return nil
+	})
+	if err != nil {
+		return m, err
+	}
+	if cardIndex := filepath.Join(conf.SavDir, cardIndexFile); FileExists(cardIndex) {
+		files[backupCardIndex] = cardIndex
+	}
+	if withDecks {
+		for name, p := range deckFiles(conf) {
+			if FileExists(p) {
+				files[name] = p
+			}
+		}
+	}
+
+	zw := zip.NewWriter(w)
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		data, err := os.ReadFile(files[name])
+		if err != nil {
+			return m, err
+		}
+		fw, err := zw.Create(name)
+		if err != nil {
+			return m, err
+		}
+		if _, err := fw.Write(data); err != nil {
+			return m, err
+		}
+		sum := sha256.Sum256(data)
+		m.Files = append(m.Files, BackupFile{Name: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])})
+	}
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return m, err
+	}
+	fw, err := zw.Create(backupManifest)
+	if err != nil {
+		return m, err
+	}
+	if _, err := fw.Write(manifest); err != nil {
+		return m, err
+	}
+	return m, zw.Close()
+}
+
+// ******************************************************
+// READING
+// ******************************************************
+
+// ReadBackup checks the manifest and the checksums of all files in the archive
+func ReadBackup(data []byte) (BackupManifest, map[string][]byte, error) {
+	m := BackupManifest{}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return m, nil, NewError("backup.invalidFile", "Err", err)
+	}
+
+	contents := map[string][]byte{}
+	for _, f := range zr.File {
+		name := path.Clean(f.Name)
+		if name != f.Name || strings.HasPrefix(name, "../") || path.IsAbs(name) {
+			return m, nil, NewError("backup.invalidFileName", "File", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return m, nil, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return m, nil, NewError("backup.fileDamaged", "File", f.Name, "Err", err)
+		}
+		contents[name] = content
+	}
+
+	manifest, ok := contents[backupManifest]
+	if !ok {
+		return m, nil, NewError("backup.noManifest")
+	}
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return m, nil, NewError("backup.fileDamaged", "File", backupManifest, "Err", err)
+	}
+	if m.App != backupApp {
+		return m, nil, NewError("backup.notFancyCards")
+	}
+	if m.Version < 1 || m.Version > backupVersion {
+		return m, nil, NewError("backup.version", "Version", m.Version, "Supported", backupVersion)
+	}
+	if checkProfileName(m.Profile) != nil {
+		return m, nil, NewError("backup.invalidProfile", "Profile", m.Profile)
+	}
+
+	files := map[string][]byte{}
+	for _, f := range m.Files {
+		content, ok := contents[f.Name]
+		if !ok {
+			return m, nil, NewError("backup.fileMissing", "File", f.Name)
+		}
+		sum := sha256.Sum256(content)
+		if int64(len(content)) != f.Size || hex.EncodeToString(sum[:]) != f.SHA256 {
+			return m, nil, NewError("backup.checksum", "File", f.Name)
+		}
+		files[f.Name] = content
+	}
+	return m, files, nil
+}
+
+// restoreTarget returns where a file of the archive belongs, "" for unknown files
+func restoreTarget(conf Config, dir string, name string) string {
+	switch {
+	case strings.HasPrefix(name, backupProfile):
+		return filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(name, backupProfile)))
+	case name == backupFileList:
+		return conf.FileListConfigFile
+	case strings.HasPrefix(name, backupDecks):
+		pair, file, ok := strings.Cut(strings.TrimPrefix(name, backupDecks), "/")
+		if ok && !strings.Contains(file, "/") {
+			return filepath.Join(conf.InputDirPrefix+pair, file)
+		}
+	}
+	return ""
+}
+
+// RestorePlan describes what a restore changes, to be confirmed by the user
+func RestorePlan(conf Config, m BackupManifest) []string {
+	plan := []string{}
+	target := ProfileDir(conf.SavDir, m.Profile)
+	if FileExists(target) {
+		plan = append(plan, T("restore.replaceProfile", "Profile", m.Profile, "Dir", target))
+	} else {
+		plan = append(plan, T("restore.createProfile", "Profile", m.Profile))
+	}
+	if m.Contains(backupCardIndex) {
+		plan = append(plan, T("restore.mergeCardIndex", "File", cardIndexFile))
+	}
+	for _, f := range m.Files {
+		if !strings.HasPrefix(f.Name, backupDecks) {
+			continue
+		}
+		if p := restoreTarget(conf, "", f.Name); FileExists(p) {
+			plan = append(plan, T("restore.replaceFile", "File", p))
+		} else if p != "" {
+			plan = append(plan, T("restore.createFile", "File", p))
+		}
+	}
+	if m.Storage != conf.Storage {
+		plan = append(plan, T("restore.storageDiffers", "Backup", m.Storage, "Configured", conf.Storage))
+	}
+	return plan
+}
+
+// RestoreBackup replaces the profile of the archive. The profile is unpacked next to
+// the profiles first, the old one is moved aside to <name>.alt and only deleted once the
+// restored one is in place, so that a failure never leaves the user without a profile.
+// It refuses if another instance uses the profile.
+func RestoreBackup(conf Config, m BackupManifest, files map[string][]byte) error {
+	target := ProfileDir(conf.SavDir, m.Profile)
+	old := target + ".alt"
+	if FileExists(old) {
+		return NewError("restore.oldExists", "Dir", old)
+	}
+	var lock *InstanceLock
+	if FileExists(target) {
+		l, err := lockProfile(conf.SavDir, m.Profile)
+		if err != nil {
+			return err
+		}
+		lock = l
+		defer func() { lock.Release() }()
+	}
+	tmp := filepath.Join(conf.SavDir, "wiederherstellung.tmp")
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		return err
+	}
+	for name, content := range files {
+		if !strings.HasPrefix(name, backupProfile) {
+			continue
+		}
+		p := restoreTarget(conf, tmp, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, content, 0644); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if lock != nil {
+		if err := os.Rename(target, old); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		if lock != nil {
+			os.Rename(old, target)
+		}
+		return err
+	}
+	if lock != nil {
+		// the lock file was moved aside with the old profile
+		lock.Release()
+		lock = nil
+		if err := os.RemoveAll(old); err != nil {
+			return err
+		}
+	}
+
+	errorList := []error{}
+	if content, ok := files[backupCardIndex]; ok {
+		errorList = append(errorList, mergeCardIndexFile(filepath.Join(conf.SavDir, cardIndexFile), content))
+	}
+	for name, content := range files {
+		if !strings.HasPrefix(name, backupDecks) {
+			continue
+		}
+		p := restoreTarget(conf, "", name)
+		if p == "" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			errorList = append(errorList, err)
+			continue
+		}
+		errorList = append(errorList, WriteFileAtomic(p, content, 0644))
+	}
+	return errors.Join(errorList...)
+}
+
+// mergeCardIndexFile adds the cards of a restored index to the card index on disk
+func mergeCardIndexFile(path string, content []byte) error {
+	restored := NewCardIndex()
+	if err := json.Unmarshal(content, &restored); err != nil {
+		return NewError("backup.fileDamaged", "File", cardIndexFile, "Err", err)
+	}
+	idx := NewCardIndex()
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &idx)
+	}
+	if idx.Cards == nil {
+		idx.Cards = map[string]CardIndexEntry{}
+	}
+	if idx.Aliases == nil {
+		idx.Aliases = map[string]string{}
+	}
+	idx.mergeFrom(restored)
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0644)
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A card's identity is derived from its language pair (as listed in the file list)
+// and its normalized text, unless the CSV file has an explicit "ID" column.
+// The card index in savDir remembers every known card, so that history survives
+// small edits: a card with an unknown ID inherits the ID of a vanished card
+// from the same file which has the same source or target text.
+
+const cardIndexFile = "karten.json"
+
+type CardIndexEntry struct {
+	LangPair string `json:"langPair"`
+	File     string `json:"file"`
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+}
+
+type CardIndex struct {
+	Cards   map[string]CardIndexEntry `json:"cards"`
+	Aliases map[string]string         `json:"aliases"` // derived ID -> ID the card's history is stored under
+	changed bool
+}
+
+func NormalizeCardText(str string) string {
+	return strings.Join(strings.Fields(strings.ToLower(str)), " ")
+}
+
+func DeriveCardID(lp LangPair, source string, target string) string {
+	sum := sha1.Sum([]byte(NormalizeCardText(source) + "\x00" + NormalizeCardText(target)))
+	return lp.ToString() + "-" + hex.EncodeToString(sum[:])[:12]
+}
+
+func ExplicitCardID(lp LangPair, id string) string {
+	return lp.ToString() + "-id-" + strings.TrimSpace(id)
+}
+
+func isExplicitCardID(id string) bool {
+	return strings.Contains(id, "-id-")
+}
+
+func findIdColumn(header []string) int {
+	for i, col := range header {
+		if strings.EqualFold(strings.TrimSpace(col), "id") {
+			return i
+		}
+	}
+	return -1
+}
+
+// canonical texts, independent of the direction the card was read in
+func (wc Card) canonicalTexts() (string, string) {
+	if wc.Reversed {
+		return wc.Target, wc.Source
+	}
+	return wc.Source, wc.Target
+}
+
+// ******************************************************
+// INDEX
+// ******************************************************
+
+func NewCardIndex() CardIndex {
+	return CardIndex{Cards: map[string]CardIndexEntry{}, Aliases: map[string]string{}}
+}
+
+// ResolveCardIDs assigns each card the ID its history is stored under and
+// registers new cards in the index
+func (idx *CardIndex) ResolveCardIDs(cards []Card) []Card {
+	present := map[string]bool{}
+	for i, wc := range cards {
+		if alias, ok := idx.Aliases[wc.ID]; ok {
+			cards[i].ID = alias
+		}
+		present[cards[i].ID] = true
+	}
+
+	for i, wc := range cards {
+		source, target := wc.canonicalTexts()
+		entry := CardIndexEntry{LangPair: wc.LangPair.ToString(), File: wc.FileName, Source: source, Target: target}
+
+		if _, known := idx.Cards[wc.ID]; !known && !isExplicitCardID(wc.ID) {
+			if orphan := idx.findOrphan(entry, present); orphan != "" {
+				idx.Aliases[wc.ID] = orphan
+				cards[i].ID = orphan
+				present[orphan] = true
+			}
+		}
+		if idx.Cards[cards[i].ID] != entry {
+			idx.Cards[cards[i].ID] = entry
+			idx.changed = true
+		}
+	}
+	return cards
+}
+
+// findOrphan looks for exactly one vanished card of the same file sharing the source or the target text
+func (idx *CardIndex) findOrphan(entry CardIndexEntry, present map[string]bool) string {
+	match := ""
+	for id, known := range idx.Cards {
+		if present[id] || known.LangPair != entry.LangPair || known.File != entry.File {
+			continue
+		}
+		if NormalizeCardText(known.Source) != NormalizeCardText(entry.Source) &&
+			NormalizeCardText(known.Target) != NormalizeCardText(entry.Target) {
+			continue
+		}
+		if match != "" {
+			return "" // ambiguous
+		}
+		match = id
+	}
+	return match
+}
+
+// mergeFrom adds cards and aliases another instance has saved in the meantime
+func (idx *CardIndex) mergeFrom(other CardIndex) {
+	for id, entry := range other.Cards {
+		if _, ok := idx.Cards[id]; !ok {
+			idx.Cards[id] = entry
+		}
+	}
+	for from, to := range other.Aliases {
+		if _, ok := idx.Aliases[from]; !ok {
+			idx.Aliases[from] = to
+		}
+	}
+}
+
+// ******************************************************
+// HANDLING FILES
+// ******************************************************
+
+// LoadCardIndex reads savDir/karten.json, an empty index if there is none
+func LoadCardIndex(savDir string) (*CardIndex, error) {
+	idx := NewCardIndex()
+	if savDir == "" {
+		return &idx, nil
+	}
+	data, err := os.ReadFile(filepath.Join(savDir, cardIndexFile))
+	if err != nil {
+		return &idx, nil
+	}
+	if err := json.Unmarshal(data, &idx); err != nil {
+		empty := NewCardIndex()
+		return &empty, err
+	}
+	if idx.Cards == nil {
+		idx.Cards = map[string]CardIndexEntry{}
+	}
+	if idx.Aliases == nil {
+		idx.Aliases = map[string]string{}
+	}
+	return &idx, nil
+}
+
+// Save writes the index to savDir, if it changed, merged with what other instances saved
+func (idx *CardIndex) Save(savDir string) error {
+	if savDir == "" || !idx.changed {
+		return nil
+	}
+	path := filepath.Join(savDir, cardIndexFile)
+	if data, err := os.ReadFile(path); err == nil {
+		disk := NewCardIndex()
+		if json.Unmarshal(data, &disk) == nil {
+			idx.mergeFrom(disk)
+		}
+	}
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(path, data, 0644); err != nil {
+		return err
+	}
+	idx.changed = false
+	return nil
+}
@@ -0,0 +1,35 @@
+package core
+
+import "testing"
+
+func TestResolveCardIDs(t *testing.T) {
+	lp := LangPair{Source: "de", Target: "fr"}
+	card := func(source, target string) Card {
+		return Card{ID: DeriveCardID(lp, source, target), LangPair: lp, FileName: "tiere.csv", Source: source, Target: target}
+	}
+	idx := NewCardIndex()
+	first := idx.ResolveCardIDs([]Card{card("Katze", "chat"), card("Hund", "chien")})
+
+	tests := []struct {
+		name   string
+		card   Card
+		wantID string
+	}{
+		{"unchanged", card("Katze", "chat"), first[0].ID},
+		{"differently written", card(" katze ", "Chat"), first[0].ID},
+		{"target edited", card("Katze", "le chat"), first[0].ID},
+		{"source edited", card("der Hund", "chien"), first[1].ID},
+		{"new card", card("Maus", "souris"), DeriveCardID(lp, "Maus", "souris")},
+	}
+	for _, tt := range tests {
+		got := idx.ResolveCardIDs([]Card{tt.card})
+		if got[0].ID != tt.wantID {
+			t.Errorf("%s: ID = %s, want %s", tt.name, got[0].ID, tt.wantID)
+		}
+	}
+
+	explicit := Card{ID: ExplicitCardID(lp, "7"), LangPair: lp, FileName: "tiere.csv", Source: "Katze", Target: "chatte"}
+	if got := idx.ResolveCardIDs([]Card{explicit}); got[0].ID != explicit.ID {
+		t.Errorf("explicit ID changed to %s", got[0].ID)
+	}
+}
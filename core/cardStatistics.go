@@ -0,0 +1,201 @@
+package core
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+type CardStats struct {
+	Attempts int       `json:"attempts"`
+	Correct  int       `json:"correct"`
+	Similar  int       `json:"similar"`
+	Wrong    int       `json:"wrong"`
+	Skipped  int       `json:"skipped"`
+	LastSeen time.Time `json:"lastSeen"`
+	Streak   int       `json:"streak"` // correct answers in a row
+}
+
+// Difficulty is the share of failed attempts, similar answers count half
+func (cs CardStats) Difficulty() float64 {
+	if cs.Attempts == 0 {
+		return 0
+	}
+	return (float64(cs.Wrong+cs.Skipped) + 0.5*float64(cs.Similar)) / float64(cs.Attempts)
+}
+
+// cardStatsDelta returns the changes of this session (memory - baseline),
+// last seen and streak are taken from memory
+func cardStatsDelta(baseline map[string]CardStats, memory map[string]CardStats) map[string]CardStats {
+	delta := map[string]CardStats{}
+	for key, value := range memory {
+		base := baseline[key]
+		if value.Attempts == base.Attempts && value.LastSeen.Equal(base.LastSeen) {
+			continue
+		}
+		delta[key] = CardStats{
+			Attempts: value.Attempts - base.Attempts,
+			Correct:  value.Correct - base.Correct,
+			Similar:  value.Similar - base.Similar,
+			Wrong:    value.Wrong - base.Wrong,
+			Skipped:  value.Skipped - base.Skipped,
+			LastSeen: value.LastSeen,
+			Streak:   value.Streak,
+		}
+	}
+	return delta
+}
+
+func (cs CardStats) AddDelta(d CardStats) CardStats {
+	cs.Attempts += d.Attempts
+	cs.Correct += d.Correct
+	cs.Similar += d.Similar
+	cs.Wrong += d.Wrong
+	cs.Skipped += d.Skipped
+	if d.LastSeen.After(cs.LastSeen) {
+		cs.LastSeen = d.LastSeen
+		cs.Streak = d.Streak
+	}
+	return cs
+}
+
+func addCardStats(st map[string]CardStats, delta map[string]CardStats) map[string]CardStats {
+	sum := maps.Clone(st)
+	for key, d := range delta {
+		sum[key] = sum[key].AddDelta(d)
+	}
+	return sum
+}
+
+// StatsKey separates the statistics of both directions of a card
+func (wc Card) StatsKey() string {
+	if wc.Reversed {
+		return wc.ID + "~r"
+	}
+	return wc.ID
+}
+
+type RankedCard struct {
+	Card  Card
+	Stats CardStats
+}
+
+// ******************************************************
+// READ/WRITE BASICS
+// ******************************************************
+
+func (cs CardStats) Record(success SuccessLevel, t time.Time) CardStats {
+	cs.Attempts++
+	cs.LastSeen = t
+	switch success {
+	case Correct:
+		cs.Correct++
+		cs.Streak++
+	case Similar:
+		cs.Similar++
+		cs.Streak = 0
+	case Wrong:
+		cs.Wrong++
+		cs.Streak = 0
+	case Skipped:
+		cs.Skipped++
+		cs.Streak = 0
+	}
+	return cs
+}
+
+// remove takes back an attempt, the streak starts again with the next one
+func (cs CardStats) remove(success SuccessLevel) CardStats {
+	cs.Attempts--
+	switch success {
+	case Correct:
+		cs.Correct--
+	case Similar:
+		cs.Similar--
+	case Wrong:
+		cs.Wrong--
+	case Skipped:
+		cs.Skipped--
+	}
+	cs.Streak = 0
+	return cs
+}
+
+func (p *Profile) LoadCardStats(wc Card) CardStats {
+	return p.cardStats[wc.StatsKey()]
+}
+
+// ******************************************************
+// LISTING
+// ******************************************************
+
+func (p *Profile) RankCards(cards []Card, less func(x, y CardStats) int, keep func(cs CardStats) bool, limit int) []RankedCard {
+	ranking := []RankedCard{}
+	for _, wc := range cards {
+		cs := p.LoadCardStats(wc)
+		if keep(cs) {
+			ranking = append(ranking, RankedCard{Card: wc, Stats: cs})
+		}
+	}
+	slices.SortStableFunc(ranking, func(x, y RankedCard) int {
+		return less(x.Stats, y.Stats)
+	})
+	if len(ranking) > limit {
+		ranking = ranking[:limit]
+	}
+	return ranking
+}
+
+func (p *Profile) HardestCards(cards []Card, limit int) []RankedCard {
+	return p.RankCards(cards, func(x, y CardStats) int {
+		if x.Difficulty() != y.Difficulty() {
+			if x.Difficulty() > y.Difficulty() {
+				return -1
+			}
+			return 1
+		}
+		return y.Attempts - x.Attempts
+	}, func(cs CardStats) bool {
+		return cs.Difficulty() > 0
+	}, limit)
+}
+
+func (p *Profile) MostSkippedCards(cards []Card, limit int) []RankedCard {
+	return p.RankCards(cards, func(x, y CardStats) int {
+		return y.Skipped - x.Skipped
+	}, func(cs CardStats) bool {
+		return cs.Skipped > 0
+	}, limit)
+}
+
+// ******************************************************
+// HANDLING FILES
+// ******************************************************
+
+func (p *Profile) saveCardStats() error {
+	if p.store == nil {
+		return nil
+	}
+	saved, err := p.store.AddCardStats(cardStatsDelta(p.savedCardStats, p.cardStats))
+	if err != nil {
+		return err
+	}
+	p.cardStats = saved
+	p.savedCardStats = maps.Clone(saved)
+	return nil
+}
+
+func (p *Profile) loadCardStats() error {
+	p.cardStats = map[string]CardStats{}
+	p.savedCardStats = map[string]CardStats{}
+	if p.store == nil {
+		return nil
+	}
+	cs, err := p.store.LoadCardStats()
+	if err != nil {
+		return err
+	}
+	p.cardStats = cs
+	p.savedCardStats = maps.Clone(cs)
+	return nil
+}
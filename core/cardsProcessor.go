@@ -0,0 +1,216 @@
+package core
+
+import (
+	"encoding/csv"
+	random "math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Card is asked in the direction it was read in: Source is shown, Target is expected
+type Card struct {
+	ID            string
+	LangPair      LangPair // as listed in the file list, even for reversed cards
+	FileName      string
+	Row           int // record in the CSV file, 0 is the header line
+	Reversed      bool
+	Source        string
+	SourceComment string
+	Target        string
+}
+
+// Deck holds the cards of a language pair in one direction, limited to some groups
+type Deck struct {
+	LangPair   LangPair // as listed in the file list
+	Reverse    bool
+	Groups     []string // empty: all groups
+	Cards      []Card
+	Characters []SpecialCharacter // of the language the answers are typed in
+	Shortcuts  bool               // replace the shortcuts of the characters in answers
+}
+
+// AskedPair is the language pair in the direction the cards are asked
+func (d Deck) AskedPair() LangPair {
+	if d.Reverse {
+		return d.LangPair.Flip()
+	}
+	return d.LangPair
+}
+
+type Randomizer struct {
+	cards    []Card
+	weights  []int // nil: all cards equally often
+	prevpos  int
+	randoSum int
+	queue    []int // positions asked first in this order, then the cards are picked randomly
+}
+
+func NewRandomizer(list []Card) Randomizer {
+	rando := Randomizer{prevpos: -1, cards: list, randoSum: len(list)}
+	return rando
+}
+
+// NewWeightedRandomizer fetches a card with a higher weight more often
+func NewWeightedRandomizer(list []Card, weights []int) Randomizer {
+	rando := Randomizer{prevpos: -1, cards: list, weights: weights}
+	for _, w := range weights {
+		rando.randoSum += w
+	}
+	return rando
+}
+
+// NewShuffledRandomizer asks every card once in random order before picking randomly
+func NewShuffledRandomizer(list []Card) Randomizer {
+	rando := NewRandomizer(list)
+	rando.queue = random.Perm(len(list))
+	return rando
+}
+
+func (rando *Randomizer) Next() Card {
+	if len(rando.queue) > 0 {
+		rando.prevpos, rando.queue = rando.queue[0], rando.queue[1:]
+		return rando.cards[rando.prevpos]
+	}
+	pos := rando.prevpos
+	for pos == rando.prevpos {
+		pos = rando.randomPos()
+		if len(rando.cards) == 1 {
+			break
+		}
+	}
+	rando.prevpos = pos
+	return rando.cards[pos]
+}
+
+func (rando *Randomizer) Len() int {
+	return len(rando.cards)
+}
+
+func (rando *Randomizer) randomPos() int {
+	n := random.IntN(rando.randoSum)
+	if rando.weights == nil {
+		return n
+	}
+	for pos, w := range rando.weights {
+		if n < w {
+			return pos
+		}
+		n -= w
+	}
+	return len(rando.cards) - 1
+}
+
+func readCardsFromCsv(mapp InputFile, lp LangPair, inputdir string, reverse bool) ([]Card, error) {
+	karten := []Card{}
+	path := filepath.Join(inputdir, mapp.FileName)
+	f, err := os.Open(path)
+	if err != nil {
+		return karten, NewError("deck.openFailed", "File", path)
+	}
+	defer f.Close()
+
+	csvReader := csv.NewReader(f)
+	csvReader.Comma = ';'
+	csvReader.LazyQuotes = false
+	inputData, err := csvReader.ReadAll()
+	if err != nil {
+		return karten, NewError("deck.readFailed", "File", path)
+	}
+
+	for i, ds := range inputData {
+
+		if i == 0 && mapp.skipHeaderLine {
+			mapp.idCol = findIdColumn(ds)
+			continue
+		}
+		if len(ds) <= mapp.sourceWordCol || len(ds) <= mapp.targetWordCol {
+			continue
+		}
+
+		var karte Card
+		if reverse {
+			karte = Card{Source: ds[mapp.targetWordCol],
+				Target: ds[mapp.sourceWordCol]}
+		} else {
+			karte = Card{Source: ds[mapp.sourceWordCol],
+				Target: ds[mapp.targetWordCol]}
+		}
+
+		if len(ds) > mapp.targetCommentCol && mapp.targetCommentCol != mapp.idCol {
+			karte.SourceComment = ds[mapp.targetCommentCol]
+		}
+
+		karte.LangPair = lp
+		karte.FileName = mapp.FileName
+		karte.Row = i
+		karte.Reversed = reverse
+		if mapp.idCol >= 0 && len(ds) > mapp.idCol && strings.TrimSpace(ds[mapp.idCol]) != "" {
+			karte.ID = ExplicitCardID(lp, ds[mapp.idCol])
+		} else {
+			karte.ID = DeriveCardID(lp, ds[mapp.sourceWordCol], ds[mapp.targetWordCol])
+		}
+		karten = append(karten, karte)
+	}
+
+	return karten, nil
+}
+
+// LoadDeck reads the cards of a language pair. With an index, each card gets the ID
+// its history is stored under, the caller saves the index if it changed.
+func LoadDeck(conf Config, index *CardIndex, lp LangPair, reverse bool, groups []string) (Deck, error) {
+	deck := Deck{LangPair: lp, Reverse: reverse, Groups: groups}
+	deck.Characters = conf.Characters[deck.AskedPair().Target]
+	deck.Shortcuts = conf.Shortcuts
+	inputfiles := conf.GetInputFiles(lp.ToString())
+
+	allCards := []Card{}
+	for _, file := range inputfiles {
+
+		// Skip files whose groups don't match at least one of the groups provided in args
+		found := false
+		for _, group := range groups {
+			if slices.Contains(file.Groups, group) {
+				found = true
+				break
+			}
+		}
+		if !found && len(groups) > 0 {
+			continue
+		}
+
+		karten, err := readCardsFromCsv(file, lp, conf.InputDir(lp), reverse)
+		if err != nil {
+			return deck, err
+		}
+		allCards = append(allCards, karten...)
+	}
+	if len(allCards) == 0 {
+		return deck, NewError("deck.empty")
+	}
+	if index != nil {
+		allCards = index.ResolveCardIDs(allCards)
+	}
+	deck.Cards = allCards
+	return deck, nil
+}
+
+// CheckDeckFiles reads every file of a language pair, unlike LoadDeck it doesn't stop
+// at the first file with an error. It returns the number of cards which could be read.
+func CheckDeckFiles(conf Config, lp LangPair) (int, []error) {
+	count := 0
+	errorList := []error{}
+	for _, file := range conf.GetInputFiles(lp.ToString()) {
+		karten, err := readCardsFromCsv(file, lp, conf.InputDir(lp), false)
+		if err != nil {
+			errorList = append(errorList, err)
+			continue
+		}
+		count += len(karten)
+	}
+	if count == 0 && len(errorList) == 0 {
+		errorList = append(errorList, NewError("deck.empty"))
+	}
+	return count, errorList
+}
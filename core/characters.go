@@ -0,0 +1,167 @@
+package core
+
+import (
+	"cmp"
+	"encoding/csv"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Letters which are hard to type on a German or US keyboard are offered as buttons below
+// the answer, per language. The list is built in, [CONFIGFILES] charactersFile replaces it
+// by a CSV file with the columns language;character;shortcut, the shortcut may be empty.
+// With [UI] shortcuts=true a shortcut like "\l/" is replaced by its letter before the answer
+// is checked. Shortcuts start with a backslash, so they can't change a normal answer
+// like "donc, alors". The built-in shortcuts are the letter followed by a mark for the accent:
+// ":" umlaut, "'" acute, "\" grave, "^" circumflex, "," cedilla or ogonek, "~" tilde,
+// "." dot, "<" caron, "/" stroke.
+
+type SpecialCharacter struct {
+	Char     string
+	Shortcut string // typed instead of Char, "" if there is none
+}
+
+// shortcutPrefix starts every shortcut
+const shortcutPrefix = `\`
+
+const defaultCharacters = `Sprache;Zeichen;Kürzel
+de;ä;\a:
+de;ö;\o:
+de;ü;\u:
+de;ß;\ss
+de;Ä;\A:
+de;Ö;\O:
+de;Ü;\U:
+fr;é;\e'
+fr;è;\e\
+fr;ê;\e^
+fr;ë;\e:
+fr;à;\a\
+fr;â;\a^
+fr;ç;\c,
+fr;î;\i^
+fr;ï;\i:
+fr;ô;\o^
+fr;ù;\u\
+fr;û;\u^
+fr;œ;\oe
+fr;É;\E'
+es;á;\a'
+es;é;\e'
+es;í;\i'
+es;ó;\o'
+es;ú;\u'
+es;ñ;\n~
+es;ü;\u:
+es;¿;\?
+es;¡;\!
+it;à;\a\
+it;è;\e\
+it;é;\e'
+it;ì;\i\
+it;ò;\o\
+it;ù;\u\
+pt;á;\a'
+pt;â;\a^
+pt;ã;\a~
+pt;à;\a\
+pt;ç;\c,
+pt;é;\e'
+pt;ê;\e^
+pt;í;\i'
+pt;ó;\o'
+pt;ô;\o^
+pt;õ;\o~
+pt;ú;\u'
+pl;ą;\a,
+pl;ć;\c'
+pl;ę;\e,
+pl;ł;\l/
+pl;ń;\n'
+pl;ó;\o'
+pl;ś;\s'
+pl;ź;\z'
+pl;ż;\z.
+pl;Ł;\L/
+pl;Ś;\S'
+pl;Ż;\Z.
+hr;č;\c<
+hr;ć;\c'
+hr;đ;\d/
+hr;š;\s<
+hr;ž;\z<
+hr;Č;\C<
+hr;Š;\S<
+hr;Ž;\Z<
+nl;ë;\e:
+nl;ï;\i:
+nl;é;\e'
+`
+
+// ReadCharactersFile reads the special characters of the languages,
+// the built-in list if the INI doesn't name a file
+func (c *Config) ReadCharactersFile() []error {
+	if c.CharactersFile == "" {
+		chars, errorList := readCharacters(strings.NewReader(defaultCharacters), "defaultCharacters")
+		c.Characters = chars
+		return errorList
+	}
+	f, err := os.Open(c.CharactersFile)
+	if err != nil {
+		return []error{NewError("deck.openFailed", "File", c.CharactersFile)}
+	}
+	defer f.Close()
+	chars, errorList := readCharacters(f, c.CharactersFile)
+	c.Characters = chars
+	return errorList
+}
+
+func readCharacters(r io.Reader, name string) (map[string][]SpecialCharacter, []error) {
+	chars := map[string][]SpecialCharacter{}
+	reader := csv.NewReader(r)
+	reader.Comma = ';'
+	reader.FieldsPerRecord = -1
+	lines, err := reader.ReadAll()
+	if err != nil {
+		return chars, []error{NewError("characters.readFailed", "File", name, "Err", err)}
+	}
+	errorList := []error{}
+	for i, line := range lines {
+		if i == 0 {
+			continue // header
+		}
+		if len(line) < 2 || strings.TrimSpace(line[0]) == "" || strings.TrimSpace(line[1]) == "" {
+			errorList = append(errorList, NewError("characters.badLine", "File", name, "Line", i+1))
+			continue
+		}
+		sc := SpecialCharacter{Char: strings.TrimSpace(line[1])}
+		if len(line) > 2 {
+			sc.Shortcut = strings.TrimSpace(line[2])
+		}
+		if sc.Shortcut != "" && (!strings.HasPrefix(sc.Shortcut, shortcutPrefix) || len(sc.Shortcut) < 2) {
+			errorList = append(errorList, NewError("characters.badShortcut", "File", name, "Line", i+1, "Shortcut", sc.Shortcut))
+			continue
+		}
+		lang := strings.TrimSpace(line[0])
+		chars[lang] = append(chars[lang], sc)
+	}
+	return chars, errorList
+}
+
+// ExpandShortcuts replaces the shortcuts in the input by their characters, longer shortcuts first
+func ExpandShortcuts(input string, chars []SpecialCharacter) string {
+	withShortcut := slices.DeleteFunc(slices.Clone(chars), func(sc SpecialCharacter) bool { return sc.Shortcut == "" })
+	if len(withShortcut) == 0 {
+		return input
+	}
+	slices.SortStableFunc(withShortcut, func(x, y SpecialCharacter) int {
+		return cmp.Compare(len(y.Shortcut), len(x.Shortcut))
+	})
+	pairs := []string{}
+	for _, sc := range withShortcut {
+		pairs = append(pairs, sc.Shortcut, sc.Char)
+	}
+	return strings.NewReplacer(pairs...).Replace(input)
+}
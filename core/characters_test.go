@@ -0,0 +1,83 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandShortcuts(t *testing.T) {
+	chars := []SpecialCharacter{
+		{Char: "ç", Shortcut: `\c,`},
+		{Char: "ł", Shortcut: `\l/`},
+		{Char: "Ł", Shortcut: `\\l/`},
+		{Char: "œ"},
+	}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"donc, alors", "donc, alors"},
+		{`gar\c,on`, "garçon"},
+		{`\l/ódź`, "łódź"},
+		{`\\l/ódź`, "Łódź"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := ExpandShortcuts(tt.input, chars); got != tt.want {
+			t.Errorf("ExpandShortcuts(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	builtIn, _ := readCharacters(strings.NewReader(defaultCharacters), "defaultCharacters")
+	builtInTests := []struct {
+		lang  string
+		input string
+		want  string
+	}{
+		{"de", `Gr\u:\sse`, "Grüße"},
+		{"fr", `tr\e\s \e'l\e\ve`, "très élève"},
+		{"fr", "il y a: donc, alors", "il y a: donc, alors"},
+		{"pl", `\Z.\o'\l/w`, "Żółw"},
+		{"es", `\?ma\n~ana?`, "¿mañana?"},
+		{"hr", `\c<a\s<a`, "čaša"},
+	}
+	for _, tt := range builtInTests {
+		if got := ExpandShortcuts(tt.input, builtIn[tt.lang]); got != tt.want {
+			t.Errorf("%s: ExpandShortcuts(%q) = %q, want %q", tt.lang, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestReadCharacters(t *testing.T) {
+	tests := []struct {
+		name   string
+		csv    string
+		chars  int
+		errors int
+	}{
+		{"built in", defaultCharacters, 71, 0},
+		{"shortcut", "Sprache;Zeichen;Kürzel\npl;ł;\\l/\n", 1, 0},
+		{"without backslash", "Sprache;Zeichen;Kürzel\nfr;ç;c,\n", 0, 1},
+		{"empty character", "Sprache;Zeichen;Kürzel\nfr;;x\n", 0, 1},
+	}
+	for _, tt := range tests {
+		chars, errorList := readCharacters(strings.NewReader(tt.csv), tt.name)
+		count := 0
+		for lang, list := range chars {
+			count += len(list)
+			shortcuts := map[string]string{}
+			for _, sc := range list {
+				if other, ok := shortcuts[sc.Shortcut]; ok && sc.Shortcut != "" {
+					t.Errorf("%s: %s and %s have the same shortcut %q in %s", tt.name, other, sc.Char, sc.Shortcut, lang)
+				}
+				shortcuts[sc.Shortcut] = sc.Char
+				if tt.name == "built in" && sc.Shortcut == "" {
+					t.Errorf("%s: %s has no shortcut", tt.name, sc.Char)
+				}
+			}
+		}
+		if count != tt.chars || len(errorList) != tt.errors {
+			t.Errorf("%s: %d characters, %d errors, want %d and %d", tt.name, count, len(errorList), tt.chars, tt.errors)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+type SuccessLevel int
+
+const (
+	Wrong SuccessLevel = iota
+	Similar
+	Correct
+	Skipped
+)
+
+var successLevelNames = []string{"wrong", "similar", "correct", "skipped"}
+
+func (s SuccessLevel) String() string {
+	if int(s) < 0 || int(s) >= len(successLevelNames) {
+		return fmt.Sprintf("SuccessLevel(%d)", int(s))
+	}
+	return successLevelNames[s]
+}
+
+func (s SuccessLevel) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *SuccessLevel) UnmarshalText(text []byte) error {
+	pos := slices.Index(successLevelNames, string(text))
+	if pos < 0 {
+		return NewError("checker.unknownSuccess", "Value", string(text))
+	}
+	*s = SuccessLevel(pos)
+	return nil
+}
+
+// MistakeKind narrows down why an answer was not correct
+type MistakeKind int
+
+const (
+	NoMistakeKind MistakeKind = iota
+	AccentMistake             // correct apart from accents/diacritics
+	TypoMistake               // one letter off
+)
+
+var mistakeKindNames = []string{"", "accent", "typo"}
+
+func (m MistakeKind) MarshalText() ([]byte, error) {
+	if int(m) < 0 || int(m) >= len(mistakeKindNames) {
+		return nil, NewError("checker.unknownMistake", "Value", int(m))
+	}
+	return []byte(mistakeKindNames[m]), nil
+}
+
+func (m *MistakeKind) UnmarshalText(text []byte) error {
+	pos := slices.Index(mistakeKindNames, string(text))
+	if pos < 0 {
+		return NewError("checker.unknownMistake", "Value", string(text))
+	}
+	*m = MistakeKind(pos)
+	return nil
+}
+
+var accentFolder = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "ą", "a",
+	"ç", "c", "ć", "c", "č", "c", "đ", "d", "ď", "d",
+	"è", "e", "é", "e", "ê", "e", "ë", "e", "ę", "e", "ě", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ł", "l",
+	"ñ", "n", "ń", "n", "ň", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ř", "r", "ś", "s", "š", "s", "ß", "ss", "ť", "t",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ů", "u",
+	"ý", "y", "ÿ", "y", "ź", "z", "ż", "z", "ž", "z",
+)
+
+type Strictness string
+
+const (
+	StrictnessLenient Strictness = "locker" // accent mistakes count as correct
+	StrictnessNormal  Strictness = "normal"
+	StrictnessStrict  Strictness = "streng" // case-sensitive, no similar answers
+)
+
+// Checker rates answers: "locker" accepts wrong accents,
+// "streng" is case-sensitive and doesn't accept similar answers
+type Checker struct {
+	Strictness Strictness
+}
+
+type Result struct {
+	Success SuccessLevel `json:"success"`
+	Mistake MistakeKind  `json:"mistake,omitempty"`
+}
+
+func (c Checker) Check(word string, wc Card) Result {
+	return Result{Success: c.successLevel(word, wc), Mistake: classifyMistake(word, wc)}
+}
+
+func (c Checker) successLevel(word string, wc Card) SuccessLevel {
+	switch c.Strictness {
+	case StrictnessStrict:
+		if word == "" {
+			return Skipped
+		} else if word == wc.Target {
+			return Correct
+		}
+		return Wrong
+	case StrictnessLenient:
+		if word != "" && foldAccents(word) == foldAccents(wc.Target) {
+			return Correct
+		}
+	}
+	in := strings.ToLower(word)
+	targ := strings.ToLower(wc.Target)
+	if word == "" {
+		return Skipped
+	} else if targ == in {
+		return Correct
+	} else if strings.Contains(targ, in) || strings.Contains(in, targ) {
+		return Similar
+	}
+	return Wrong
+}
+
+// Feedback tells the user how an answer was rated
+func (r Result) Feedback() string {
+	text := ""
+	switch r.Success {
+	case Wrong:
+		text = T("feedback.wrong")
+	case Similar:
+		text = T("feedback.similar")
+	case Correct:
+		text = T("feedback.correct")
+	case Skipped:
+		text = T("feedback.skipped")
+	}
+	switch r.Mistake {
+	case AccentMistake:
+		text += " " + T("feedback.accentMistake")
+	case TypoMistake:
+		text += " " + T("feedback.typoMistake")
+	}
+	return text
+}
+
+func foldAccents(str string) string {
+	return accentFolder.Replace(strings.ToLower(str))
+}
+
+// sameLetter compares two letters like successLevel compares answers
+func (c Checker) sameLetter(x rune, y rune) bool {
+	switch c.Strictness {
+	case StrictnessStrict:
+		return x == y
+	case StrictnessLenient:
+		return foldAccents(string(x)) == foldAccents(string(y))
+	}
+	return unicode.ToLower(x) == unicode.ToLower(y)
+}
+
+// classifyMistake narrows down why an answer was not correct, independent of the strictness
+func classifyMistake(word string, wc Card) MistakeKind {
+	in := strings.ToLower(word)
+	targ := strings.ToLower(wc.Target)
+	if word == "" || in == targ {
+		return NoMistakeKind
+	}
+	if foldAccents(in) == foldAccents(targ) {
+		return AccentMistake
+	}
+	if editDistance(in, targ) == 1 {
+		return TypoMistake
+	}
+	return NoMistakeKind
+}
+
+// editDistance is the Levenshtein distance between two strings, counted in runes
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
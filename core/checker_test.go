@@ -0,0 +1,28 @@
+package core
+
+import "testing"
+
+func TestChecker(t *testing.T) {
+	card := Card{Target: "le garçon"}
+	tests := []struct {
+		strictness Strictness
+		input      string
+		want       Result
+	}{
+		{StrictnessNormal, "le garçon", Result{Success: Correct}},
+		{StrictnessNormal, "Le Garçon", Result{Success: Correct}},
+		{StrictnessNormal, "garçon", Result{Success: Similar}},
+		{StrictnessNormal, "le garcon", Result{Success: Wrong, Mistake: AccentMistake}},
+		{StrictnessNormal, "le garçin", Result{Success: Wrong, Mistake: TypoMistake}},
+		{StrictnessNormal, "", Result{Success: Skipped}},
+		{StrictnessLenient, "le garcon", Result{Success: Correct, Mistake: AccentMistake}},
+		{StrictnessStrict, "Le garçon", Result{Success: Wrong}},
+		{StrictnessStrict, "garçon", Result{Success: Wrong}},
+		{StrictnessStrict, "le garçon", Result{Success: Correct}},
+	}
+	for _, tt := range tests {
+		if got := (Checker{Strictness: tt.strictness}).Check(tt.input, card); got != tt.want {
+			t.Errorf("%s: Check(%q) = %+v, want %+v", tt.strictness, tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	ini "gopkg.in/ini.v1"
+)
+
+var defaultIni string = `[LANGUAGES]
+de=Deutsch
+pl=Polnisch
+en=Englisch
+fr=Französisch
+es=Spanisch
+it=Italienisch
+pt=Portugiesisch
+nl=Niederländisch
+hr=Kroatisch
+
+[CONFIGFILES]
+fileListConfigFile=config/dateien.csv
+inputDirPrefix=input_
+savDir=sav
+; json oder sqlite
+storage=json
+; Sonderzeichen pro Sprache als CSV (Sprache;Zeichen;Kürzel), leer: eingebaute Liste
+charactersFile=
+
+[UI]
+; Sprache der Oberfläche: de oder en, leer: Sprache des Systems
+language=
+; Kürzel der Sonderzeichen (z. B. \l/ für ł) in Antworten ersetzen: true oder false
+shortcuts=false
+
+[KEYS]
+; Tasten beim Üben, z. B. F2 oder Ctrl+H (auch Alt+, Shift+)
+check=Return
+menu=Escape
+skip=Ctrl+S
+hint=Ctrl+H
+correct=Ctrl+R
+`
+
+type InputFile struct {
+	FileName         string
+	Groups           []string
+	sourceWordCol    int
+	targetWordCol    int
+	sourceCommentCol int
+	targetCommentCol int
+	idCol            int // -1 if the file has no "ID" column in its header line
+	skipHeaderLine   bool
+}
+
+func defaultInputFile() InputFile {
+	k := InputFile{
+		sourceWordCol:    0,
+		targetWordCol:    1,
+		sourceCommentCol: 2,
+		skipHeaderLine:   true,
+		targetCommentCol: 3,
+		idCol:            -1,
+	}
+	return k
+}
+
+type LangPair struct {
+	Source string
+	Target string
+}
+
+func (l LangPair) ToString() string {
+	return l.Source + "_" + l.Target
+}
+
+func (l LangPair) Flip() LangPair {
+	return LangPair{Source: l.Target, Target: l.Source}
+}
+
+// Config is read from fancyCards.ini and the file list it points to
+type Config struct {
+	LanguageNames      map[string]string
+	LangPairs          []LangPair
+	SavDir             string
+	Storage            string // json or sqlite
+	InputDirPrefix     string
+	FileListConfigFile string
+	CharactersFile     string                        // "" uses the built-in list, see characters.go
+	Characters         map[string][]SpecialCharacter // by language code
+	UILanguage         string                        // [UI] language, "" uses the language of the system
+	Shortcuts          bool                          // [UI] shortcuts, replace the shortcuts of the characters in answers
+	Keys               map[string]Shortcut           // [KEYS] by action, e.g. Keys[KeyHint]
+	files              map[string][]InputFile
+}
+
+func (c Config) Init() Config {
+	c.LanguageNames = map[string]string{}
+	c.InputDirPrefix = ""
+	c.FileListConfigFile = ""
+	c.Storage = storageJSON
+	c.LangPairs = []LangPair{}
+	c.files = map[string][]InputFile{}
+	c.Keys, _ = readKeys(nil)
+	return c
+}
+
+func (c *Config) AddFile(fn string, lp LangPair) {
+	km := defaultInputFile()
+	km.FileName = fn
+	c.files[lp.ToString()] = append(c.files[lp.ToString()], km)
+}
+
+func (c *Config) ValidateAndAddFile(file InputFile, lp LangPair, lineNo int) error {
+	if c.GetLangName(lp.Source) == "" {
+		return NewError("config.invalidSourceLang", "File", c.FileListConfigFile, "Lang", lp.Source, "Line", lineNo+1)
+	} else if c.GetLangName(lp.Target) == "" {
+		return NewError("config.invalidTargetLang", "File", c.FileListConfigFile, "Lang", lp.Target, "Line", lineNo+1)
+	}
+
+	c.files[lp.ToString()] = append(c.files[lp.ToString()], file)
+	if !c.LangPairExists(lp) {
+		c.LangPairs = append(c.LangPairs, lp)
+	}
+	return nil
+}
+
+func (c Config) GetInputFiles(lpst string) []InputFile {
+	return c.files[lpst]
+}
+
+func (c Config) GetGroups(lp LangPair) []string {
+	groupList := make([]string, 0)
+	for _, file := range c.GetInputFiles(lp.ToString()) {
+		for _, group := range file.Groups {
+			if !slices.Contains(groupList, group) {
+				groupList = append(groupList, group)
+			}
+		}
+	}
+	return groupList
+}
+
+func (c Config) GetLangName(id string) string {
+	return string(c.LanguageNames[id])
+}
+
+func (c Config) GetLangPairAsString(lp LangPair) string {
+	sl := c.GetLangName(lp.Source)
+	tl := c.GetLangName(lp.Target)
+	lpstr := fmt.Sprintf("%s -> %s", sl, tl)
+	return lpstr
+}
+
+func (c Config) LangPairExists(lp LangPair) bool {
+	for _, existingLP := range c.LangPairs {
+		if lp.ToString() == existingLP.ToString() {
+			return true
+		}
+	}
+	return false
+}
+
+func processLanguageFileLine(input []string, i int) (InputFile, LangPair, error) {
+	f := defaultInputFile()
+	f.Groups = make([]string, 0)
+	lp := LangPair{}
+	var err error
+
+	if len(input) < 3 {
+		err = NewError("config.lineTooShort", "Line", i+1)
+	} else if input[0] == "" || input[1] == "" || input[2] == "" {
+		err = NewError("config.lineEmptyColumns", "Line", i+1)
+	} else {
+		lp.Source = input[0]
+		lp.Target = input[1]
+		f.FileName = input[2]
+
+		if len(input) >= 4 {
+			groupsRaw := strings.Split(input[3], ",")
+			for _, str := range groupsRaw {
+				if strings.Trim(str, " ") != "" {
+					f.Groups = append(f.Groups, strings.Trim(str, " "))
+				}
+			}
+		}
+	}
+	return f, lp, err
+}
+
+func (c *Config) ReadLanguagesFile() []error {
+	errorList := []error{}
+	f, err := os.Open(c.FileListConfigFile)
+	if err != nil {
+		return []error{err}
+	}
+	// Lese Sprachen aus CSV-Datei ein
+	reader := csv.NewReader(f)
+	reader.Comma = ';'
+
+	inputData, err := reader.ReadAll()
+	if err != nil {
+		return []error{err}
+	}
+	for i, ds := range inputData {
+		if i == 0 {
+			continue
+		}
+		file, lp, err := processLanguageFileLine(ds, i)
+		if err != nil {
+			errorList = append(errorList, err)
+			continue
+		}
+		err = c.ValidateAndAddFile(file, lp, i)
+		if err != nil {
+			errorList = append(errorList, err)
+		}
+
+	}
+
+	return errorList
+}
+
+func LoadConfig(inipath string) (Config, []error) {
+	c := Config{}.Init()
+	errorList := []error{}
+	CardsIniReader, err := ini.Load(inipath)
+	if err != nil {
+		return c, []error{err}
+	}
+
+	langSection := CardsIniReader.Section("LANGUAGES")
+	for _, k := range langSection.Keys() {
+		langKey := k.Name()
+		c.LanguageNames[langKey] = k.String()
+	}
+
+	configfilesSection := CardsIniReader.Section("CONFIGFILES")
+	for _, k := range configfilesSection.Keys() {
+		val := k.String()
+		switch k.Name() {
+		case "inputDirPrefix":
+			c.InputDirPrefix = val
+		case "fileListConfigFile":
+			c.FileListConfigFile = val
+		case "savDir":
+			c.SavDir = val
+		case "storage":
+			c.Storage = val
+		case "charactersFile":
+			c.CharactersFile = val
+		}
+	}
+
+	c.UILanguage = CardsIniReader.Section("UI").Key("language").String()
+	c.Shortcuts = CardsIniReader.Section("UI").Key("shortcuts").MustBool(false)
+	keys, keyErrors := readKeys(CardsIniReader.Section("KEYS").KeysHash())
+	c.Keys = keys
+	c.resolvePaths(filepath.Dir(inipath))
+
+	if len(configfilesSection.Keys()) == 0 {
+		errorList = append(errorList, NewError("config.noConfigFiles", "File", inipath))
+	}
+	if c.InputDirPrefix == "" || c.FileListConfigFile == "" {
+		errorList = append(errorList, NewError("config.missingPaths", "File", inipath))
+	}
+	if len(c.LanguageNames) == 0 {
+		errorList = append(errorList, NewError("config.noLanguages", "File", inipath))
+	}
+
+	if len(errorList) == 0 {
+		errorList = c.ReadLanguagesFile()
+	}
+	errorList = append(errorList, c.ReadCharactersFile()...)
+	// wrong keys fall back to the defaults, the decks can be read anyway
+	errorList = append(errorList, keyErrors...)
+
+	return c, errorList
+}
+
+func CreateDefaultIni(inipath string) error {
+	if err := os.MkdirAll(filepath.Dir(inipath), 0755); err != nil {
+		return err
+	}
+	data := []byte(defaultIni)
+	return os.WriteFile(inipath, data, 0644)
+}
+
+// resolvePaths makes relative paths of the INI relative to the directory of the INI
+// instead of the current directory, which depends on how the app was started
+func (c *Config) resolvePaths(iniDir string) {
+	for _, p := range []*string{&c.FileListConfigFile, &c.SavDir, &c.InputDirPrefix, &c.CharactersFile} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(iniDir, *p)
+		}
+	}
+}
+
+// InputDir is the directory with the CSV files of a language pair
+func (c Config) InputDir(lp LangPair) string {
+	return c.InputDirPrefix + lp.ToString()
+}
+
+// FindLangPair returns the language pair of the file list and the direction
+// for a pair like "de_fr", "fr_de" is the reverse direction of "de_fr"
+func (c Config) FindLangPair(pair string) (LangPair, bool, error) {
+	for _, lp := range c.LangPairs {
+		if lp.ToString() == pair {
+			return lp, false, nil
+		}
+		if lp.Flip().ToString() == pair && !c.LangPairExists(lp.Flip()) {
+			return lp, true, nil
+		}
+	}
+	return LangPair{}, false, NewError("config.unknownPair", "Pair", pair, "File", c.FileListConfigFile)
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Editing cards rewrites the CSV file of the card. A card is found by its row, which
+// must still hold the card's texts, so that an edit based on an outdated deck doesn't
+// overwrite another card. History survives an edit of either the source or the target
+// text, see CardIndex.
+
+// CardText is what can be edited, in the direction the card is asked
+type CardText struct {
+	Source  string
+	Target  string
+	Comment string
+}
+
+// Validate checks the texts before they are written to a CSV file
+func (t CardText) Validate() error {
+	if strings.TrimSpace(t.Source) == "" || strings.TrimSpace(t.Target) == "" {
+		return NewError("edit.emptyText")
+	}
+	if strings.ContainsAny(t.Source+t.Target+t.Comment, "\r\n") {
+		return NewError("edit.lineBreak")
+	}
+	return nil
+}
+
+// AddCard appends a card to a file of the language pair as listed in the file list.
+// It returns the card as it was written, LoadDeck gives it the ID of its history.
+func AddCard(conf Config, lp LangPair, fileName string, reversed bool, text CardText) (Card, error) {
+	if err := text.Validate(); err != nil {
+		return Card{}, err
+	}
+	file, ok := conf.findInputFile(lp, fileName)
+	if !ok {
+		return Card{}, NewError("edit.fileNotListed", "File", fileName, "Pair", lp.ToString())
+	}
+	path := filepath.Join(conf.InputDir(lp), file.FileName)
+	rows, err := readCsvRows(path)
+	if err != nil {
+		return Card{}, err
+	}
+	if len(rows) == 0 && file.skipHeaderLine {
+		rows = append(rows, []string{conf.GetLangName(lp.Source), conf.GetLangName(lp.Target)})
+	}
+	row := file.setTexts([]string{}, file.idColumn(rows), reversed, text)
+	rows = append(rows, row)
+	wc := Card{
+		ID:            DeriveCardID(lp, row[file.sourceWordCol], row[file.targetWordCol]),
+		LangPair:      lp,
+		FileName:      file.FileName,
+		Row:           len(rows) - 1,
+		Reversed:      reversed,
+		Source:        text.Source,
+		SourceComment: text.Comment,
+		Target:        text.Target,
+	}
+	return wc, writeCsvRows(path, rows)
+}
+
+// UpdateCard replaces the texts of a card
+func UpdateCard(conf Config, wc Card, text CardText) error {
+	if err := text.Validate(); err != nil {
+		return err
+	}
+	return editCardRow(conf, wc, func(rows [][]string, file InputFile) [][]string {
+		rows[wc.Row] = file.setTexts(rows[wc.Row], file.idColumn(rows), wc.Reversed, text)
+		return rows
+	})
+}
+
+func DeleteCard(conf Config, wc Card) error {
+	return editCardRow(conf, wc, func(rows [][]string, file InputFile) [][]string {
+		return slices.Delete(rows, wc.Row, wc.Row+1)
+	})
+}
+
+func editCardRow(conf Config, wc Card, edit func(rows [][]string, file InputFile) [][]string) error {
+	file, ok := conf.findInputFile(wc.LangPair, wc.FileName)
+	if !ok {
+		return NewError("edit.fileNotListed", "File", wc.FileName, "Pair", wc.LangPair.ToString())
+	}
+	path := filepath.Join(conf.InputDir(wc.LangPair), file.FileName)
+	rows, err := readCsvRows(path)
+	if err != nil {
+		return err
+	}
+	source, target := wc.canonicalTexts()
+	if (wc.Row == 0 && file.skipHeaderLine) || wc.Row < 0 || wc.Row >= len(rows) ||
+		len(rows[wc.Row]) <= max(file.sourceWordCol, file.targetWordCol) ||
+		rows[wc.Row][file.sourceWordCol] != source || rows[wc.Row][file.targetWordCol] != target {
+		return NewError("edit.changed", "Card", wc.Source, "File", path)
+	}
+	return writeCsvRows(path, edit(rows, file))
+}
+
+func (c Config) findInputFile(lp LangPair, fileName string) (InputFile, bool) {
+	for _, file := range c.GetInputFiles(lp.ToString()) {
+		if file.FileName == fileName {
+			return file, true
+		}
+	}
+	return InputFile{}, false
+}
+
+// idColumn is the "ID" column of the header line, -1 if there is none
+func (f InputFile) idColumn(rows [][]string) int {
+	if !f.skipHeaderLine || len(rows) == 0 {
+		return -1
+	}
+	return findIdColumn(rows[0])
+}
+
+// setTexts writes the texts into the columns of the file, keeping all other columns
+func (f InputFile) setTexts(row []string, idCol int, reversed bool, text CardText) []string {
+	source, target := text.Source, text.Target
+	if reversed {
+		source, target = target, source
+	}
+	row = padRow(row, max(f.sourceWordCol, f.targetWordCol)+1)
+	row[f.sourceWordCol] = source
+	row[f.targetWordCol] = target
+	if f.targetCommentCol != idCol && (text.Comment != "" || len(row) > f.targetCommentCol) {
+		row = padRow(row, f.targetCommentCol+1)
+		row[f.targetCommentCol] = text.Comment
+	}
+	return row
+}
+
+func padRow(row []string, width int) []string {
+	for len(row) < width {
+		row = append(row, "")
+	}
+	return row
+}
+
+// writeCsvRows gives all rows the same number of columns, as readCardsFromCsv expects
+func writeCsvRows(path string, rows [][]string) error {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	for i := range rows {
+		rows[i] = padRow(rows[i], width)
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,217 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Every answer is appended to a JSON Lines file in the directory "antworten" of the profile.
+// There is one file per month, which is continued in a new file
+// ("antworten-2025-03.2.jsonl", ...) once it reaches maxEventLogSize.
+// The aggregated statistics can be rebuilt from these files.
+
+const (
+	eventLogDir     = "antworten"
+	maxEventLogSize = 10 << 20
+)
+
+type AnswerEvent struct {
+	ID         string       `json:"id"`
+	Time       time.Time    `json:"time"`
+	LangPair   string       `json:"langPair"` // in the direction the card was asked
+	Reverse    bool         `json:"reverse"`
+	CardID     string       `json:"cardId"`
+	Input      string       `json:"input"`
+	Success    SuccessLevel `json:"success"`
+	Mistake    MistakeKind  `json:"mistake,omitempty"`
+	ResponseMs int64        `json:"responseMs"`
+
+	// An answer the user marked as correct is logged again with a new ID and the new rating,
+	// Corrects is the ID of the first event and Corrected its rating
+	Corrects  string  `json:"corrects,omitempty"`
+	Corrected *Result `json:"corrected,omitempty"`
+}
+
+func NewEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// StatsKey matches Card.StatsKey of the card which was asked
+func (e AnswerEvent) StatsKey() string {
+	if e.Reverse {
+		return e.CardID + "~r"
+	}
+	return e.CardID
+}
+
+// ******************************************************
+// WRITING
+// ******************************************************
+
+func eventLogFile(dir string, t time.Time) string {
+	base := filepath.Join(dir, eventLogDir, fmt.Sprintf("antworten-%s", t.Format("2006-01")))
+	path := base + ".jsonl"
+	for part := 2; ; part++ {
+		info, err := os.Stat(path)
+		if err != nil || info.Size() < maxEventLogSize {
+			return path
+		}
+		path = fmt.Sprintf("%s.%d.jsonl", base, part)
+	}
+}
+
+func AppendEvent(dir string, e AnswerEvent) error {
+	if err := os.MkdirAll(filepath.Join(dir, eventLogDir), 0755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(eventLogFile(dir, e.Time), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Result is the rating of the logged answer
+func (e AnswerEvent) Result() Result {
+	return Result{Success: e.Success, Mistake: e.Mistake}
+}
+
+// Feedback tells the user how the answer was rated, also if they marked it as correct
+func (e AnswerEvent) Feedback() string {
+	if e.Corrected != nil {
+		return T("feedback.markedCorrect")
+	}
+	return e.Result().Feedback()
+}
+
+// dayKey is the key of the daily statistics the answer counts for
+func (e AnswerEvent) dayKey() string {
+	return e.LangPair + "_" + e.Time.Format(DayLayout)
+}
+
+// countIn adds the answer to the statistics of its day, a correction replaces the rating it corrects
+func (e AnswerEvent) countIn(s Stats) Stats {
+	if e.Corrected != nil {
+		s = s.Sub(Stats{}.Record(e.Corrected.Success, e.Corrected.Mistake))
+	}
+	return s.Record(e.Success, e.Mistake)
+}
+
+// countInCard adds the answer to the statistics of its card
+func (e AnswerEvent) countInCard(cs CardStats) CardStats {
+	if e.Corrected != nil {
+		cs = cs.remove(e.Corrected.Success)
+	}
+	return cs.Record(e.Success, e.Time)
+}
+
+// ******************************************************
+// READING
+// ******************************************************
+
+// ReadEvents returns all logged answers in chronological order
+func ReadEvents(dir string) ([]AnswerEvent, error) {
+	files, err := filepath.Glob(filepath.Join(dir, eventLogDir, "antworten-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	events := []AnswerEvent{}
+	for _, path := range files {
+		fileEvents, err := readEventFile(path)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, fileEvents...)
+	}
+	slices.SortStableFunc(events, func(x, y AnswerEvent) int { return x.Time.Compare(y.Time) })
+	return events, nil
+}
+
+func readEventFile(path string) ([]AnswerEvent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	events := []AnswerEvent{}
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		var e AnswerEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			if i == len(lines)-1 {
+				break // a crash may leave a half-written last line
+			}
+			return events, NewError("log.badLine", "File", path, "Line", i+1, "Err", err)
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// RebuildStatistics recalculates the aggregated statistics from the answer log.
+// Days before the first logged answer are taken over from the existing statistics,
+// as they were recorded before the log was introduced.
+func RebuildStatistics(existing map[string]Stats, events []AnswerEvent) map[string]Stats {
+	rebuilt := map[string]Stats{}
+	if len(events) == 0 {
+		return maps.Clone(existing)
+	}
+	firstDay := events[0].Time.Format(DayLayout)
+	for key, value := range existing {
+		if _, day := SplitStatsKey(key); day < firstDay {
+			rebuilt[key] = value
+		}
+	}
+	for _, e := range events {
+		rebuilt[e.dayKey()] = e.countIn(rebuilt[e.dayKey()])
+	}
+	return rebuilt
+}
+
+// RebuildStatisticsFromLog replaces the statistics of the profile by the ones rebuilt from its log
+func (p *Profile) RebuildStatisticsFromLog() error {
+	if p.store == nil {
+		return nil
+	}
+	events, err := ReadEvents(p.Dir)
+	if err != nil {
+		return err
+	}
+	return WithSaveLock(p.Dir, func() error {
+		st, err := p.store.LoadStatistics()
+		if err != nil {
+			return err
+		}
+		// the changes of this session are in the log as well
+		rebuilt := RebuildStatistics(st, events)
+		if err := p.store.ReplaceStatistics(rebuilt); err != nil {
+			return err
+		}
+		p.statistics = rebuilt
+		p.savedStatistics = maps.Clone(rebuilt)
+		return nil
+	})
+}
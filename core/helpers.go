@@ -0,0 +1,66 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func FileExists(path string) bool {
+	_, err := os.Stat(path)
+	if err == nil {
+		return true
+	}
+	if os.IsNotExist(err) {
+		return false
+	}
+	return false // some other error (permission, IO)
+}
+
+// WriteFileAtomic writes to a temporary file in the same directory, syncs it to disk
+// and renames it to path, so that path either contains the old or the new data
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpName, perm)
+	}
+	if err == nil {
+		err = os.Rename(tmpName, path)
+	}
+	if err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	// make the rename itself durable, not supported on every platform
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// RemoveStaleTempFiles deletes leftovers of WriteFileAtomic after a crash. Only files older
+// than maxAge are removed, a younger one may still be written by another instance.
+// Callers hold the save lock, see WithSaveLock.
+func RemoveStaleTempFiles(path string, maxAge time.Duration) {
+	matches, _ := filepath.Glob(path + ".tmp*")
+	for _, m := range matches {
+		if isStale(m, maxAge) {
+			os.Remove(m)
+		}
+	}
+}
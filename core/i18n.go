@@ -0,0 +1,132 @@
+package core
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+)
+
+// Texts shown to the user are looked up by message ID in the catalogs in translations/,
+// e.g. T("api.unknownPair", "Pair", "de_fr"). The arguments are name/value pairs for the
+// placeholders of the text, like {{.Pair}}. German is the complete catalog, a text
+// missing in another catalog is shown in German.
+
+//go:embed translations/*.json
+var translationFiles embed.FS
+
+const defaultLanguage = "de"
+
+var (
+	bundle    *i18n.Bundle
+	localizer *i18n.Localizer
+	uiLang    = defaultLanguage
+	uiLangs   []language.Tag
+)
+
+func init() {
+	bundle = i18n.NewBundle(language.German)
+	bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
+	files, _ := translationFiles.ReadDir("translations")
+	for _, f := range files {
+		mf, err := bundle.LoadMessageFileFS(translationFiles, "translations/"+f.Name())
+		if err != nil {
+			panic(err) // the catalogs are embedded, so this is a bug
+		}
+		uiLangs = append(uiLangs, mf.Tag)
+	}
+	SetLanguage(defaultLanguage)
+}
+
+// Languages lists the languages of the user interface, e.g. "de" and "en"
+func Languages() []string {
+	langs := []string{}
+	for _, tag := range uiLangs {
+		base, _ := tag.Base()
+		langs = append(langs, base.String())
+	}
+	return langs
+}
+
+// SetLanguage switches the user interface to the closest supported language
+func SetLanguage(lang string) {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		tag = language.German
+	}
+	_, i, confidence := language.NewMatcher(uiLangs).Match(tag)
+	if confidence == language.No {
+		uiLang = defaultLanguage
+	} else {
+		uiLang = Languages()[i]
+	}
+	localizer = i18n.NewLocalizer(bundle, uiLang)
+}
+
+func Language() string {
+	return uiLang
+}
+
+// SystemLanguage reads the language of the environment, e.g. "en" for LANG=en_US.UTF-8.
+// It is "" if none is set.
+func SystemLanguage() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		val := os.Getenv(env)
+		if val == "" || val == "C" || val == "POSIX" {
+			continue
+		}
+		if parts := strings.FieldsFunc(val, func(r rune) bool { return r == '_' || r == '.' || r == '-' }); len(parts) > 0 {
+			return strings.ToLower(parts[0])
+		}
+	}
+	return ""
+}
+
+// T returns the text of the message ID in the current language,
+// args are pairs of placeholder names and values
+func T(id string, args ...any) string {
+	data := map[string]any{}
+	for i := 0; i+1 < len(args); i += 2 {
+		if name, ok := args[i].(string); ok {
+			data[name] = args[i+1]
+		}
+	}
+	text, err := localizer.Localize(&i18n.LocalizeConfig{MessageID: id, TemplateData: data})
+	if err != nil && text == "" {
+		return id
+	}
+	return text
+}
+
+// ******************************************************
+// ERRORS
+// ******************************************************
+
+// localizedError keeps the message ID instead of the text,
+// so it is shown in the language which is set when it is displayed
+type localizedError struct {
+	id   string
+	args []any
+}
+
+func (e localizedError) Error() string {
+	return T(e.id, e.args...)
+}
+
+// NewError creates an error with a message ID, see T
+func NewError(id string, args ...any) error {
+	return localizedError{id: id, args: args}
+}
+
+// ErrorID returns the message ID of an error, "" if it has none
+func ErrorID(err error) string {
+	var le localizedError
+	if errors.As(err, &le) {
+		return le.id
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Only one instance should own a profile's directory. The owner refreshes the lock file
+// regularly, a lock file which hasn't been refreshed for a while is considered stale
+// (e.g. after a crash) and is taken over.
+// An instance which doesn't own the lock still works: all saves merge the changes
+// of this session into what is on disk, see SaveStatistics.
+
+const (
+	lockFileName      = "fancyCards.lock"
+	saveLockFileName  = "speichern.lock"
+	lockHeartbeat     = 30 * time.Second
+	lockStaleAfter    = 2 * time.Minute
+	saveLockStaleTime = 10 * time.Second
+)
+
+type InstanceLock struct {
+	Pid     int       `json:"pid"`
+	Host    string    `json:"host"`
+	Started time.Time `json:"started"`
+	Token   string    `json:"token"` // tells this lock from the one of an instance which took over
+	path    string
+	stop    chan struct{}
+}
+
+type LockedError struct {
+	owner InstanceLock
+}
+
+func (e LockedError) Error() string {
+	return T("lock.running", "Host", e.owner.Host, "Pid", e.owner.Pid, "Started", e.owner.Started.Format(T("format.dateTime")))
+}
+
+func isStale(path string, maxAge time.Duration) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return true
+	}
+	return time.Since(info.ModTime()) > maxAge
+}
+
+// AcquireInstanceLock returns a LockedError if another live instance owns the save directory
+func AcquireInstanceLock(dir string) (*InstanceLock, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, lockFileName)
+	host, _ := os.Hostname()
+	lock := &InstanceLock{Pid: os.Getpid(), Host: host, Started: time.Now(), Token: rand.Text(), path: path, stop: make(chan struct{})}
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, os.ErrExist) {
+		if !isStale(path, lockStaleAfter) {
+			owner := InstanceLock{}
+			content, _ := os.ReadFile(path)
+			json.Unmarshal(content, &owner)
+			return nil, LockedError{owner: owner}
+		}
+		os.Remove(path)
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, err = f.Write(data)
+	f.Close()
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	go lock.heartbeat()
+	return lock, nil
+}
+
+func (l *InstanceLock) heartbeat() {
+	ticker := time.NewTicker(lockHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case t := <-ticker.C:
+			if !l.owned() {
+				return
+			}
+			os.Chtimes(l.path, t, t)
+		}
+	}
+}
+
+// owned tells if the lock file is still ours, another instance may have taken it over
+// while this one was suspended
+func (l *InstanceLock) owned() bool {
+	content, err := os.ReadFile(l.path)
+	if err != nil {
+		return false
+	}
+	owner := InstanceLock{}
+	return json.Unmarshal(content, &owner) == nil && owner.Token == l.Token
+}
+
+// Release removes the lock file unless another instance owns it by now
+func (l *InstanceLock) Release() {
+	if l == nil {
+		return
+	}
+	close(l.stop)
+	if l.owned() {
+		os.Remove(l.path)
+	}
+}
+
+// WithSaveLock keeps other instances from saving at the same time,
+// so that reading, merging and writing the files is not interleaved
+func WithSaveLock(dir string, fn func() error) error {
+	path := filepath.Join(dir, saveLockFileName)
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return err
+		}
+		if isStale(path, saveLockStaleTime) {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return NewError("lock.saveLocked", "File", path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	defer os.Remove(path)
+	return fn()
+}
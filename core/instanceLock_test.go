@@ -0,0 +1,37 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInstanceLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, lockFileName)
+	first, err := AcquireInstanceLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AcquireInstanceLock(dir); !errors.As(err, &LockedError{}) {
+		t.Fatalf("second lock: %v, want a LockedError", err)
+	}
+
+	// the first instance hangs, the second one takes over the stale lock
+	old := time.Now().Add(-2 * lockStaleAfter)
+	os.Chtimes(path, old, old)
+	second, err := AcquireInstanceLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Release()
+	if !FileExists(path) {
+		t.Fatal("the first instance removed the lock of the second one")
+	}
+	second.Release()
+	if FileExists(path) {
+		t.Error("the lock file was kept")
+	}
+}
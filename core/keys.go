@@ -0,0 +1,114 @@
+package core
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// The keys of the practice view are set in the [KEYS] section of the INI, e.g. hint=Ctrl+H.
+// The key names are the ones of fyne, so a front end can use them directly.
+
+const (
+	KeyCheck   = "check"   // check the answer, then continue with the next card
+	KeyMenu    = "menu"    // back to the main menu
+	KeySkip    = "skip"    // skip the card
+	KeyHint    = "hint"    // show one more letter of the solution
+	KeyCorrect = "correct" // mark the last answer as correct
+)
+
+// KeyActions in the order they are listed in the instructions
+var KeyActions = []string{KeyCheck, KeyMenu, KeySkip, KeyHint, KeyCorrect}
+
+var defaultKeys = map[string]string{
+	KeyCheck:   "Return",
+	KeyMenu:    "Escape",
+	KeySkip:    "Ctrl+S",
+	KeyHint:    "Ctrl+H",
+	KeyCorrect: "Ctrl+R",
+}
+
+var keyNames = []string{
+	"Escape", "Return", "Tab", "BackSpace", "Insert", "Delete", "Right", "Left", "Down", "Up",
+	"Prior", "Next", "Home", "End", "Space",
+	"F1", "F2", "F3", "F4", "F5", "F6", "F7", "F8", "F9", "F10", "F11", "F12",
+}
+
+// typingKeys edit the answer, so they need Ctrl or Alt as well as letters and digits
+var typingKeys = []string{"BackSpace", "Delete", "Right", "Left", "Home", "End", "Space"}
+
+type Shortcut struct {
+	Key   string // e.g. "Return", "F2" or "H"
+	Ctrl  bool
+	Alt   bool
+	Shift bool
+}
+
+// ParseShortcut reads a shortcut like "Escape", "F2" or "Ctrl+Shift+H"
+func ParseShortcut(text string) (Shortcut, error) {
+	sc := Shortcut{}
+	parts := strings.Split(text, "+")
+	for _, mod := range parts[:len(parts)-1] {
+		switch strings.ToLower(strings.TrimSpace(mod)) {
+		case "ctrl", "control":
+			sc.Ctrl = true
+		case "alt":
+			sc.Alt = true
+		case "shift":
+			sc.Shift = true
+		default:
+			return sc, NewError("keys.invalid", "Key", text)
+		}
+	}
+	key := strings.TrimSpace(parts[len(parts)-1])
+	if i := slices.IndexFunc(keyNames, func(name string) bool { return strings.EqualFold(name, key) }); i >= 0 {
+		sc.Key = keyNames[i]
+	} else if len(key) == 1 && strings.ContainsAny(strings.ToUpper(key), "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789") {
+		sc.Key = strings.ToUpper(key)
+	} else {
+		return sc, NewError("keys.invalid", "Key", text)
+	}
+	// Shift alone only changes what is typed
+	if (len(sc.Key) == 1 || slices.Contains(typingKeys, sc.Key) || sc.Shift) && !sc.Ctrl && !sc.Alt {
+		return sc, NewError("keys.needsModifier", "Key", text)
+	}
+	return sc, nil
+}
+
+func (sc Shortcut) String() string {
+	text := ""
+	if sc.Ctrl {
+		text += "Ctrl+"
+	}
+	if sc.Alt {
+		text += "Alt+"
+	}
+	if sc.Shift {
+		text += "Shift+"
+	}
+	return text + sc.Key
+}
+
+// readKeys takes the defaults for the actions which are not in the section
+func readKeys(section map[string]string) (map[string]Shortcut, []error) {
+	keys := map[string]Shortcut{}
+	errorList := []error{}
+	for _, action := range slices.Sorted(maps.Keys(section)) {
+		if _, ok := defaultKeys[action]; !ok {
+			errorList = append(errorList, NewError("keys.unknownAction", "Action", action, "Actions", strings.Join(KeyActions, ", ")))
+		}
+	}
+	for _, action := range KeyActions {
+		text, ok := section[action]
+		if !ok {
+			text = defaultKeys[action]
+		}
+		sc, err := ParseShortcut(text)
+		if err != nil {
+			errorList = append(errorList, err)
+			sc, _ = ParseShortcut(defaultKeys[action])
+		}
+		keys[action] = sc
+	}
+	return keys, errorList
+}
@@ -0,0 +1,50 @@
+package core
+
+import "testing"
+
+func TestParseShortcut(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    Shortcut
+		wantErr bool
+	}{
+		{"Return", Shortcut{Key: "Return"}, false},
+		{"escape", Shortcut{Key: "Escape"}, false},
+		{"F2", Shortcut{Key: "F2"}, false},
+		{"Ctrl+H", Shortcut{Key: "H", Ctrl: true}, false},
+		{"ctrl + shift + s", Shortcut{Key: "S", Ctrl: true, Shift: true}, false},
+		{"Alt+Left", Shortcut{Key: "Left", Alt: true}, false},
+		{"H", Shortcut{}, true},         // would be typed into the answer
+		{"Shift+H", Shortcut{}, true},   // an upper case letter
+		{"BackSpace", Shortcut{}, true}, // edits the answer
+		{"Meta+H", Shortcut{}, true},
+		{"Ctrl+", Shortcut{}, true},
+		{"Ctrl+Ä", Shortcut{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseShortcut(tt.text)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseShortcut(%q) error = %v, want error %v", tt.text, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseShortcut(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestReadKeys(t *testing.T) {
+	keys, errorList := readKeys(map[string]string{KeyHint: "F2", KeySkip: "S", "jump": "F3"})
+	if len(errorList) != 2 {
+		t.Errorf("got %d errors, want 2 (unknown action, skip without modifier)", len(errorList))
+	}
+	if keys[KeyHint].String() != "F2" {
+		t.Errorf("hint = %s, want F2", keys[KeyHint])
+	}
+	if keys[KeySkip].String() != defaultKeys[KeySkip] {
+		t.Errorf("skip = %s, want the default %s", keys[KeySkip], defaultKeys[KeySkip])
+	}
+	if len(keys) != len(KeyActions) {
+		t.Errorf("got %d keys, want one per action", len(keys))
+	}
+}
@@ -0,0 +1,288 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+)
+
+// Merging the learning state of another device, e.g. a savDir copied from a laptop.
+// Answers from the other answer log are taken over by their event ID, so merging the
+// same data again doesn't count anything twice. For the time before the other log started
+// only the statistics of the other device are available: they are added and remembered
+// under the ID of the device in geraete.json, so merging again only adds what changed.
+// The directory which is merged from is only read.
+
+type MergeResult struct {
+	Source     string
+	NewEvents  int
+	MergedDays int
+}
+
+// ResolveMergeSource finds the directory with the data of the profile in a copied
+// savDir, a copied profile directory or a savDir from before there were profiles
+func ResolveMergeSource(dir string, profile string) (string, error) {
+	if !FileExists(filepath.Join(dir, profilesDir)) {
+		return dir, nil
+	}
+	profiles := ListProfiles(dir)
+	switch {
+	case FileExists(ProfileDir(dir, profile)):
+		return ProfileDir(dir, profile), nil
+	case len(profiles) == 1:
+		return ProfileDir(dir, profiles[0]), nil
+	}
+	return "", NewError("merge.severalProfiles", "Dir", dir)
+}
+
+// readMergeSource reads the statistics and card statistics of another profile without changing it
+func readMergeSource(dir string) (map[string]Stats, map[string]CardStats, error) {
+	if FileExists(filepath.Join(dir, sqliteFileName)) {
+		st, cs, migrated, err := readSQLiteFile(dir)
+		if err != nil || migrated {
+			return st, cs, err
+		}
+		// the JSON files weren't imported into the database yet
+		jsonSt, jsonCs, err := readJSONFiles(dir)
+		return addStatistics(st, jsonSt), addCardStats(cs, jsonCs), err
+	}
+	return readJSONFiles(dir)
+}
+
+// newEvents returns the events of the other log which are not in the local one
+func newEvents(local []AnswerEvent, other []AnswerEvent) []AnswerEvent {
+	known := map[string]bool{}
+	for _, e := range local {
+		known[e.ID] = true
+	}
+	events := []AnswerEvent{}
+	for _, e := range other {
+		if !known[e.ID] {
+			known[e.ID] = true
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// MergeStatistics adds the new events and what changed in the statistics other devices
+// recorded before their logs started: imported is what was taken over from them so far,
+// update what they recorded now. It returns the statistics and the number of changed days.
+func MergeStatistics(local map[string]Stats, imported map[string]Stats, update map[string]Stats, events []AnswerEvent) (map[string]Stats, int) {
+	delta := statisticsDelta(imported, update)
+	merged := addStatistics(local, delta)
+	for _, e := range events {
+		merged[e.dayKey()] = e.countIn(merged[e.dayKey()])
+	}
+	return merged, len(delta)
+}
+
+// MergeCardStats adds the new events and what changed in the card statistics other devices
+// recorded before their logs started, see MergeStatistics
+func MergeCardStats(local map[string]CardStats, imported map[string]CardStats, update map[string]CardStats, events []AnswerEvent) map[string]CardStats {
+	merged := maps.Clone(local)
+	for _, e := range events {
+		merged[e.StatsKey()] = merged[e.StatsKey()].AddDelta(e.countInCard(CardStats{}))
+	}
+	return addCardStats(merged, cardStatsDelta(imported, update))
+}
+
+// MergeFrom merges the learning state in dir into the profile
+func (p *Profile) MergeFrom(dir string) (MergeResult, error) {
+	result := MergeResult{}
+	if p.store == nil {
+		return result, NewError("merge.noProfile")
+	}
+	source, err := ResolveMergeSource(dir, p.Name)
+	if err != nil {
+		return result, err
+	}
+	result.Source = source
+	if isSameDir(source, p.Dir) {
+		return result, NewError("merge.sameProfile")
+	}
+
+	otherStats, otherCardStats, err := readMergeSource(source)
+	if err != nil {
+		return result, err
+	}
+	otherEvents, err := ReadEvents(source)
+	if err != nil {
+		return result, err
+	}
+	otherDevices, err := loadDevices(source)
+	if err != nil {
+		return result, err
+	}
+	// the other device's own statistics and the ones it took over from further devices
+	updates := maps.Clone(otherDevices.Imported)
+	otherID := otherDevices.ID
+	if otherID == "" {
+		// merged from a version without device IDs, the path keeps merging again idempotent
+		otherID, _ = filepath.Abs(source)
+	}
+	updates[otherID] = DeviceStats{
+		Statistics: preLogStatistics(otherStats, otherEvents, otherDevices.Imported),
+		CardStats:  preLogCardStats(otherCardStats, otherEvents, otherDevices.Imported),
+	}
+
+	err = WithSaveLock(p.Dir, func() error {
+		if err := errors.Join(p.saveStatistics(), p.saveCardStats()); err != nil {
+			return err
+		}
+		localEvents, err := ReadEvents(p.Dir)
+		if err != nil {
+			return err
+		}
+		devices, err := ensureDeviceID(p.Dir)
+		if err != nil {
+			return err
+		}
+		// a copy of this profile contains this device's own statistics
+		delete(updates, devices.ID)
+		imported, update := DeviceStats{}, DeviceStats{}
+		for id, ds := range updates {
+			imported = imported.add(devices.Imported[id])
+			update = update.add(ds)
+			devices.Imported[id] = ds
+		}
+
+		events := newEvents(localEvents, otherEvents)
+		for _, e := range events {
+			if err := AppendEvent(p.Dir, e); err != nil {
+				return err
+			}
+		}
+		result.NewEvents = len(events)
+
+		p.statistics, result.MergedDays = MergeStatistics(p.statistics, imported.Statistics, update.Statistics, events)
+		p.cardStats = MergeCardStats(p.cardStats, imported.CardStats, update.CardStats, events)
+		if err := errors.Join(p.saveStatistics(), p.saveCardStats()); err != nil {
+			return err
+		}
+		return saveDevices(p.Dir, devices)
+	})
+	return result, err
+}
+
+func isSameDir(x string, y string) bool {
+	infoX, errX := os.Stat(x)
+	infoY, errY := os.Stat(y)
+	return errX == nil && errY == nil && os.SameFile(infoX, infoY)
+}
+
+// ******************************************************
+// DEVICES
+// ******************************************************
+
+const devicesFile = "geraete.json"
+
+// DeviceStats is what a device recorded before its answer log started
+type DeviceStats struct {
+	Statistics map[string]Stats     `json:"statistics"`
+	CardStats  map[string]CardStats `json:"cardStats"`
+}
+
+func (ds DeviceStats) add(o DeviceStats) DeviceStats {
+	return DeviceStats{
+		Statistics: addStatistics(addStatistics(map[string]Stats{}, ds.Statistics), o.Statistics),
+		CardStats:  addCardStats(addCardStats(map[string]CardStats{}, ds.CardStats), o.CardStats),
+	}
+}
+
+type devices struct {
+	ID       string                 `json:"id"`       // of the profile, a copy of the directory keeps it
+	Imported map[string]DeviceStats `json:"imported"` // taken over from other devices, by their ID
+}
+
+// loadDevices returns an empty ID if the profile has none yet
+func loadDevices(dir string) (devices, error) {
+	d := devices{}
+	data, err := os.ReadFile(filepath.Join(dir, devicesFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return d, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &d); err != nil {
+			return d, NewError("file.readFailed", "File", filepath.Join(dir, devicesFile), "Err", err)
+		}
+	}
+	if d.Imported == nil {
+		d.Imported = map[string]DeviceStats{}
+	}
+	return d, nil
+}
+
+// ensureDeviceID gives the profile an ID, callers hold the save lock
+func ensureDeviceID(dir string) (devices, error) {
+	d, err := loadDevices(dir)
+	if err != nil || d.ID != "" {
+		return d, err
+	}
+	d.ID = NewEventID()
+	return d, saveDevices(dir, d)
+}
+
+func saveDevices(dir string, d devices) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(filepath.Join(dir, devicesFile), data, 0644)
+}
+
+// preLogStatistics returns the statistics a device recorded itself before its log started:
+// the days up to the first logged answer (all days without a log), without the logged
+// answers of that day and without what it took over from other devices
+func preLogStatistics(st map[string]Stats, events []AnswerEvent, imported map[string]DeviceStats) map[string]Stats {
+	own := map[string]Stats{}
+	for key, value := range st {
+		if _, day := SplitStatsKey(key); len(events) == 0 || day <= events[0].Time.Format(DayLayout) {
+			own[key] = value
+		}
+	}
+	for _, e := range events {
+		if value, ok := own[e.dayKey()]; ok {
+			own[e.dayKey()] = value.Sub(e.countIn(Stats{}))
+		}
+	}
+	for _, ds := range imported {
+		for key, value := range ds.Statistics {
+			own[key] = own[key].Sub(value)
+		}
+	}
+	for key, value := range own {
+		if value.Count <= 0 {
+			delete(own, key)
+		}
+	}
+	return own
+}
+
+// preLogCardStats returns the card statistics a device recorded itself before its log
+// started: what its logged answers and the other devices don't explain
+func preLogCardStats(cs map[string]CardStats, events []AnswerEvent, imported map[string]DeviceStats) map[string]CardStats {
+	explained := map[string]CardStats{}
+	for _, e := range events {
+		explained[e.StatsKey()] = explained[e.StatsKey()].AddDelta(e.countInCard(CardStats{}))
+	}
+	for _, ds := range imported {
+		explained = addCardStats(explained, ds.CardStats)
+	}
+	own := map[string]CardStats{}
+	for key, value := range cs {
+		e := explained[key]
+		value.Attempts -= e.Attempts
+		value.Correct -= e.Correct
+		value.Similar -= e.Similar
+		value.Wrong -= e.Wrong
+		value.Skipped -= e.Skipped
+		if value.Attempts > 0 {
+			own[key] = value
+		}
+	}
+	return own
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestMergeStatistics(t *testing.T) {
+	day := "de_fr_2024-01-05"
+	event := AnswerEvent{ID: "e1", Time: time.Date(2024, 2, 1, 10, 0, 0, 0, time.Local), LangPair: "de_fr", Success: Correct}
+	tests := []struct {
+		name     string
+		local    map[string]Stats
+		imported map[string]Stats
+		update   map[string]Stats
+		events   []AnswerEvent
+		want     map[string]Stats
+		days     int
+	}{
+		{
+			name:   "same day on both devices is summed",
+			local:  map[string]Stats{day: {Count: 5, Successes: 5}},
+			update: map[string]Stats{day: {Count: 3, Mistakes: 3}},
+			want:   map[string]Stats{day: {Count: 8, Successes: 5, Mistakes: 3}},
+			days:   1,
+		},
+		{
+			name:     "merging again adds nothing",
+			local:    map[string]Stats{day: {Count: 8, Successes: 5, Mistakes: 3}},
+			imported: map[string]Stats{day: {Count: 3, Mistakes: 3}},
+			update:   map[string]Stats{day: {Count: 3, Mistakes: 3}},
+			want:     map[string]Stats{day: {Count: 8, Successes: 5, Mistakes: 3}},
+			days:     0,
+		},
+		{
+			name:     "only the change since the last merge is added",
+			local:    map[string]Stats{day: {Count: 8, Successes: 5, Mistakes: 3}},
+			imported: map[string]Stats{day: {Count: 3, Mistakes: 3}},
+			update:   map[string]Stats{day: {Count: 4, Successes: 1, Mistakes: 3}},
+			want:     map[string]Stats{day: {Count: 9, Successes: 6, Mistakes: 3}},
+			days:     1,
+		},
+		{
+			name:   "new events are counted",
+			local:  map[string]Stats{},
+			events: []AnswerEvent{event},
+			want:   map[string]Stats{"de_fr_2024-02-01": {Count: 1, Successes: 1}},
+		},
+	}
+	for _, tt := range tests {
+		local := maps.Clone(tt.local)
+		got, days := MergeStatistics(tt.local, tt.imported, tt.update, tt.events)
+		if !maps.Equal(got, tt.want) || days != tt.days {
+			t.Errorf("%s: got %v, %d days, want %v, %d days", tt.name, got, days, tt.want, tt.days)
+		}
+		if !maps.Equal(tt.local, local) {
+			t.Errorf("%s: the local statistics were changed", tt.name)
+		}
+	}
+}
+
+func TestMergeCardStats(t *testing.T) {
+	seen := time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC)
+	later := seen.Add(time.Hour)
+	tests := []struct {
+		name     string
+		local    CardStats
+		imported CardStats
+		update   CardStats
+		want     CardStats
+	}{
+		{
+			name:   "local counts are kept",
+			local:  CardStats{Attempts: 2, Correct: 1, Wrong: 1, LastSeen: seen, Streak: 0},
+			update: CardStats{Attempts: 5, Correct: 5, LastSeen: later, Streak: 5},
+			want:   CardStats{Attempts: 7, Correct: 6, Wrong: 1, LastSeen: later, Streak: 5},
+		},
+		{
+			name:     "merging again adds nothing",
+			local:    CardStats{Attempts: 7, Correct: 6, Wrong: 1, LastSeen: later, Streak: 5},
+			imported: CardStats{Attempts: 5, Correct: 5, LastSeen: later, Streak: 5},
+			update:   CardStats{Attempts: 5, Correct: 5, LastSeen: later, Streak: 5},
+			want:     CardStats{Attempts: 7, Correct: 6, Wrong: 1, LastSeen: later, Streak: 5},
+		},
+		{
+			name:   "an older other card keeps the local streak",
+			local:  CardStats{Attempts: 3, Correct: 3, LastSeen: later, Streak: 3},
+			update: CardStats{Attempts: 1, Wrong: 1, LastSeen: seen},
+			want:   CardStats{Attempts: 4, Correct: 3, Wrong: 1, LastSeen: later, Streak: 3},
+		},
+	}
+	for _, tt := range tests {
+		imported := map[string]CardStats{}
+		if tt.imported.Attempts > 0 {
+			imported["c1"] = tt.imported
+		}
+		got := MergeCardStats(map[string]CardStats{"c1": tt.local}, imported, map[string]CardStats{"c1": tt.update}, nil)
+		if got["c1"] != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got["c1"], tt.want)
+		}
+	}
+}
+
+func TestPreLogStatistics(t *testing.T) {
+	logStart := time.Date(2024, 2, 1, 10, 0, 0, 0, time.Local)
+	events := []AnswerEvent{
+		{ID: "e1", Time: logStart, LangPair: "de_fr", Success: Correct},
+		{ID: "e2", Time: logStart.Add(time.Hour), LangPair: "de_fr", Success: Wrong},
+		{ID: "e3", Time: logStart.AddDate(0, 0, 1), LangPair: "de_fr", Success: Correct},
+	}
+	imported := map[string]DeviceStats{"other": {Statistics: map[string]Stats{"de_fr_2024-01-05": {Count: 2, Successes: 2}}}}
+	tests := []struct {
+		name string
+		st   map[string]Stats
+		want map[string]Stats
+	}{
+		{
+			name: "days before the log",
+			st:   map[string]Stats{"de_fr_2024-01-05": {Count: 5, Successes: 5}},
+			want: map[string]Stats{"de_fr_2024-01-05": {Count: 3, Successes: 3}},
+		},
+		{
+			name: "explained by the log",
+			st:   map[string]Stats{"de_fr_2024-02-01": {Count: 2, Successes: 1, Mistakes: 1}, "de_fr_2024-02-02": {Count: 1, Successes: 1}},
+			want: map[string]Stats{},
+		},
+		{
+			name: "answers before the first event of its day",
+			st:   map[string]Stats{"de_fr_2024-02-01": {Count: 5, Successes: 3, Mistakes: 2}},
+			want: map[string]Stats{"de_fr_2024-02-01": {Count: 3, Successes: 2, Mistakes: 1}},
+		},
+	}
+	for _, tt := range tests {
+		if got := preLogStatistics(tt.st, events, imported); !maps.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	cs := map[string]CardStats{"c1": {Attempts: 4, Correct: 4, LastSeen: logStart, Streak: 4}}
+	cardEvents := []AnswerEvent{{ID: "e1", Time: logStart, CardID: "c1", Success: Correct}}
+	got := preLogCardStats(cs, cardEvents, nil)
+	if got["c1"].Attempts != 3 || got["c1"].Correct != 3 {
+		t.Errorf("got %+v, want 3 attempts before the log", got["c1"])
+	}
+}
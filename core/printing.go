@@ -0,0 +1,212 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// Printing decks as A4 PDFs, without anything from the internet. The Go fonts are
+// embedded, they cover Latin Extended-A, so e.g. Polish and Croatian letters work.
+
+type PrintLayout int
+
+const (
+	FlashcardLayout PrintLayout = iota // fronts and backs on alternating pages, for duplex printing
+	WorksheetLayout                    // a list to fill in, followed by the answer key
+)
+
+const (
+	pageWidth  = 210.0 // A4 in mm
+	pageHeight = 297.0
+	pageMargin = 15.0
+
+	flashcardCols   = 2
+	flashcardRows   = 5
+	flashcardWidth  = (pageWidth - 2*pageMargin) / flashcardCols
+	flashcardHeight = (pageHeight - 2*pageMargin) / flashcardRows
+	flashcardPad    = 4.0
+	maxCardFontSize = 20.0
+	minCardFontSize = 8.0
+
+	fontFamily = "Go"
+)
+
+// WritePDF prints the cards of the deck in the direction they are asked
+func WritePDF(w io.Writer, conf Config, deck Deck, layout PrintLayout) error {
+	if len(deck.Cards) == 0 {
+		return NewError("print.noCards")
+	}
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(fontFamily, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", gobold.TTF)
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetTitle(printTitle(conf, deck), true)
+	pdf.SetCreator("fancyCards", true)
+
+	switch layout {
+	case FlashcardLayout:
+		writeFlashcards(pdf, deck.Cards)
+	case WorksheetLayout:
+		writeWorksheet(pdf, printTitle(conf, deck), deck.Cards)
+	default:
+		return NewError("print.unknownLayout", "Layout", int(layout))
+	}
+	return pdf.Output(w)
+}
+
+func printTitle(conf Config, deck Deck) string {
+	title := conf.GetLangPairAsString(deck.AskedPair())
+	if len(deck.Groups) > 0 {
+		title += fmt.Sprintf(" (%s)", strings.Join(deck.Groups, ", "))
+	}
+	return title
+}
+
+// ******************************************************
+// FLASHCARDS
+// ******************************************************
+
+// writeFlashcards puts the sources on odd and the targets on even pages. The columns of
+// the backs are mirrored, so that both sides line up when printed double-sided and
+// flipped on the long edge. The margins are the same on both sides for this.
+func writeFlashcards(pdf *fpdf.Fpdf, cards []Card) {
+	pdf.SetAutoPageBreak(false, 0)
+	perPage := flashcardCols * flashcardRows
+	for start := 0; start < len(cards); start += perPage {
+		sheet := cards[start:min(start+perPage, len(cards))]
+		pdf.AddPage()
+		for i, wc := range sheet {
+			x := pageMargin + float64(i%flashcardCols)*flashcardWidth
+			y := pageMargin + float64(i/flashcardCols)*flashcardHeight
+			drawFlashcard(pdf, x, y, wc.Source, "")
+		}
+		pdf.AddPage()
+		for i, wc := range sheet {
+			x := pageMargin + float64(flashcardCols-1-i%flashcardCols)*flashcardWidth
+			y := pageMargin + float64(i/flashcardCols)*flashcardHeight
+			drawFlashcard(pdf, x, y, wc.Target, wc.SourceComment)
+		}
+	}
+}
+
+// drawFlashcard draws the cutting lines and centers the text, smaller if it's long
+func drawFlashcard(pdf *fpdf.Fpdf, x, y float64, text, comment string) {
+	pdf.SetDrawColor(180, 180, 180)
+	pdf.SetDashPattern([]float64{1, 1}, 0)
+	pdf.Rect(x, y, flashcardWidth, flashcardHeight, "D")
+	pdf.SetDashPattern([]float64{}, 0)
+
+	textWidth := flashcardWidth - 2*flashcardPad
+	textHeight := flashcardHeight - 2*flashcardPad
+	commentHeight := 0.0
+	if comment != "" {
+		commentHeight = 6
+	}
+
+	var lines []string
+	size := maxCardFontSize
+	for {
+		pdf.SetFont(fontFamily, "B", size)
+		lines = pdf.SplitText(text, textWidth)
+		if float64(len(lines))*lineHeight(size) <= textHeight-commentHeight || size <= minCardFontSize {
+			break
+		}
+		size--
+	}
+	// too long even at the smallest size: cut it
+	if fit := int((textHeight - commentHeight) / lineHeight(size)); len(lines) > fit {
+		lines = lines[:max(fit, 1)]
+		lines[len(lines)-1] = fitText(pdf, lines[len(lines)-1]+" …", textWidth)
+	}
+	top := y + flashcardPad + (textHeight-commentHeight-float64(len(lines))*lineHeight(size))/2
+	for i, line := range lines {
+		pdf.SetXY(x+flashcardPad, top+float64(i)*lineHeight(size))
+		pdf.CellFormat(textWidth, lineHeight(size), line, "", 0, "C", false, 0, "")
+	}
+
+	if comment != "" {
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.SetTextColor(100, 100, 100)
+		pdf.SetXY(x+flashcardPad, y+flashcardHeight-flashcardPad-commentHeight)
+		pdf.CellFormat(textWidth, commentHeight, fitText(pdf, comment, textWidth), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	}
+}
+
+// lineHeight converts a font size in points to the height of a line in mm
+func lineHeight(size float64) float64 {
+	return size * 0.3528 * 1.25
+}
+
+// fitText cuts the text to one line of the width
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// ******************************************************
+// WORKSHEET
+// ******************************************************
+
+const (
+	worksheetRow    = 9.0
+	worksheetNumber = 12.0
+)
+
+// writeWorksheet lists the sources with space for the answers, the answer key starts on a new page
+func writeWorksheet(pdf *fpdf.Fpdf, title string, cards []Card) {
+	pdf.SetAutoPageBreak(true, pageMargin+8)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pageMargin - 4)
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.SetTextColor(100, 100, 100)
+		pdf.CellFormat(0, 5, T("print.footer", "Title", title, "Page", pdf.PageNo()), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+	columnWidth := (pageWidth - 2*pageMargin - worksheetNumber) / 2
+
+	pdf.AddPage()
+	worksheetHeader(pdf, title)
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.CellFormat(0, 7, T("print.nameDate", "Name", "_______________________", "Date", "______________"), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+	pdf.SetDrawColor(150, 150, 150)
+	for i, wc := range cards {
+		pdf.SetFont(fontFamily, "", 11)
+		pdf.CellFormat(worksheetNumber, worksheetRow, fmt.Sprintf("%d.", i+1), "", 0, "R", false, 0, "")
+		pdf.CellFormat(columnWidth, worksheetRow, fitText(pdf, wc.Source, columnWidth), "", 0, "L", false, 0, "")
+		pdf.CellFormat(columnWidth, worksheetRow, "", "B", 1, "L", false, 0, "")
+	}
+
+	pdf.AddPage()
+	worksheetHeader(pdf, T("print.answerKey", "Title", title))
+	for i, wc := range cards {
+		pdf.SetFont(fontFamily, "", 11)
+		pdf.CellFormat(worksheetNumber, worksheetRow-2, fmt.Sprintf("%d.", i+1), "", 0, "R", false, 0, "")
+		pdf.CellFormat(columnWidth, worksheetRow-2, fitText(pdf, wc.Source, columnWidth), "", 0, "L", false, 0, "")
+		pdf.SetFont(fontFamily, "B", 11)
+		pdf.CellFormat(columnWidth, worksheetRow-2, fitText(pdf, wc.Target, columnWidth), "", 1, "L", false, 0, "")
+	}
+}
+
+func worksheetHeader(pdf *fpdf.Fpdf, title string) {
+	pdf.SetFont(fontFamily, "B", 16)
+	pdf.CellFormat(0, 10, title, "", 1, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.CellFormat(0, 5, "fancyCards, "+time.Now().Format(T("format.date")), "", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(3)
+}
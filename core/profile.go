@@ -0,0 +1,359 @@
+package core
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Each learner profile has its own directory savDir/profile/<name> with statistics,
+// card statistics, the answer log and its preferences.
+// The card index stays in savDir, as it describes the decks, which all profiles share.
+
+const (
+	profilesDir        = "profile"
+	DefaultProfileName = "Standard"
+	preferencesFile    = "einstellungen.json"
+)
+
+type PracticeMode string
+
+const (
+	PracticeRandom  PracticeMode = "zufall"     // all cards equally often
+	PracticeHardest PracticeMode = "schwierige" // difficult cards more often
+)
+
+type Preferences struct {
+	PracticeMode   PracticeMode `json:"practiceMode"`
+	Strictness     Strictness   `json:"strictness"`
+	PIN            string       `json:"pin,omitempty"`            // salted hash, see HashPIN
+	SessionCards   int          `json:"sessionCards,omitempty"`   // cards per session, 0: no limit
+	SessionMinutes int          `json:"sessionMinutes,omitempty"` // minutes per session, 0: no limit
+}
+
+func DefaultPreferences() Preferences {
+	return Preferences{PracticeMode: PracticeRandom, Strictness: StrictnessNormal}
+}
+
+// A PIN has only a few digits, so the hash has to be slow to keep it from being tried out
+// by anyone who can read einstellungen.json
+const (
+	pinHashName       = "pbkdf2-sha256"
+	pinHashIterations = 600_000
+)
+
+// HashPIN returns "pbkdf2-sha256$<iterations>$<salt>$<key>", the PIN itself isn't stored
+func HashPIN(pin string) string {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	key, _ := pbkdf2.Key(sha256.New, pin, salt, pinHashIterations, sha256.Size)
+	return fmt.Sprintf("%s$%d$%s$%s", pinHashName, pinHashIterations, hex.EncodeToString(salt), hex.EncodeToString(key))
+}
+
+// CheckPIN accepts any PIN if the profile has none. PINs saved before the hash was
+// slow ("<salt>$<sha256 of salt and PIN>") are still accepted, see OutdatedPIN.
+func (prefs Preferences) CheckPIN(pin string) bool {
+	if prefs.PIN == "" {
+		return true
+	}
+	parts := strings.Split(prefs.PIN, "$")
+	switch {
+	case len(parts) == 2:
+		sum := sha256.Sum256([]byte(parts[0] + pin))
+		return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(parts[1])) == 1
+	case len(parts) == 4 && parts[0] == pinHashName:
+		iterations, err := strconv.Atoi(parts[1])
+		salt, saltErr := hex.DecodeString(parts[2])
+		want, keyErr := hex.DecodeString(parts[3])
+		if err != nil || saltErr != nil || keyErr != nil || iterations <= 0 {
+			return false
+		}
+		key, err := pbkdf2.Key(sha256.New, pin, salt, iterations, len(want))
+		return err == nil && subtle.ConstantTimeCompare(key, want) == 1
+	}
+	return false
+}
+
+// OutdatedPIN tells if the PIN should be hashed again with HashPIN after it was checked
+func (prefs Preferences) OutdatedPIN() bool {
+	return prefs.PIN != "" && !strings.HasPrefix(prefs.PIN, fmt.Sprintf("%s$%d$", pinHashName, pinHashIterations))
+}
+
+// files which belonged to savDir before there were profiles
+var profileFiles = []string{"statistik.json", "_statistik.json", "karten_statistik.json", "fancyCards.db", eventLogDir}
+
+// Profile holds the statistics of a learner while they are practicing
+type Profile struct {
+	Name  string
+	Dir   string // empty if nothing is saved
+	Prefs Preferences
+
+	store      StatsStore
+	statistics map[string]Stats
+	cardStats  map[string]CardStats
+	lock       *InstanceLock
+
+	// as last loaded from/saved to disk, to merge with changes of other instances
+	savedStatistics map[string]Stats
+	savedCardStats  map[string]CardStats
+}
+
+// ******************************************************
+// HANDLING FILES
+// ******************************************************
+
+func ProfileDir(savDir string, name string) string {
+	return filepath.Join(savDir, profilesDir, name)
+}
+
+func ListProfiles(savDir string) []string {
+	entries, err := os.ReadDir(filepath.Join(savDir, profilesDir))
+	if err != nil {
+		return []string{}
+	}
+	names := []string{}
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func checkProfileName(name string) error {
+	if name == "" {
+		return NewError("profile.emptyName")
+	}
+	if strings.ContainsAny(name, `/\:*?"<>|`) || name == "." || name == ".." {
+		return NewError("profile.invalidName", "Profile", name)
+	}
+	return nil
+}
+
+func ValidateProfileName(savDir string, name string) error {
+	if err := checkProfileName(name); err != nil {
+		return err
+	}
+	if FileExists(ProfileDir(savDir, name)) {
+		return NewError("profile.exists", "Profile", name)
+	}
+	return nil
+}
+
+func CreateProfile(savDir string, name string) error {
+	name = strings.TrimSpace(name)
+	if err := ValidateProfileName(savDir, name); err != nil {
+		return err
+	}
+	return os.MkdirAll(ProfileDir(savDir, name), 0755)
+}
+
+// lockProfile takes the instance lock of a profile before its directory is changed,
+// it refuses if another instance uses the profile
+func lockProfile(savDir string, name string) (*InstanceLock, error) {
+	if err := checkProfileName(name); err != nil {
+		return nil, err
+	}
+	dir := ProfileDir(savDir, name)
+	if !FileExists(dir) {
+		return nil, NewError("profile.unknown", "Profile", name)
+	}
+	lock, err := AcquireInstanceLock(dir)
+	var locked LockedError
+	if errors.As(err, &locked) {
+		return nil, NewError("profile.inUse", "Profile", name, "Host", locked.owner.Host, "Pid", locked.owner.Pid)
+	}
+	return lock, err
+}
+
+func RenameProfile(savDir string, oldName string, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if err := ValidateProfileName(savDir, newName); err != nil {
+		return err
+	}
+	lock, err := lockProfile(savDir, oldName)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(ProfileDir(savDir, oldName), ProfileDir(savDir, newName))
+	lock.Release()
+	if err == nil {
+		// the lock file moved along
+		os.Remove(filepath.Join(ProfileDir(savDir, newName), lockFileName))
+	}
+	return err
+}
+
+// DeleteProfile deletes the directory of a profile, unless another instance uses it
+func DeleteProfile(savDir string, name string) error {
+	lock, err := lockProfile(savDir, name)
+	if err != nil {
+		return err
+	}
+	dir := ProfileDir(savDir, name)
+	entries, err := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.Name() != lockFileName {
+			err = errors.Join(err, os.RemoveAll(filepath.Join(dir, e.Name())))
+		}
+	}
+	lock.Release()
+	if err != nil {
+		return err
+	}
+	return os.Remove(dir)
+}
+
+// MigrateToProfiles moves the data of an installation without profiles into the default profile
+func MigrateToProfiles(savDir string) error {
+	if FileExists(filepath.Join(savDir, profilesDir)) {
+		return nil
+	}
+	target := ProfileDir(savDir, DefaultProfileName)
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return err
+	}
+	for _, name := range profileFiles {
+		path := filepath.Join(savDir, name)
+		if FileExists(path) {
+			if err := os.Rename(path, filepath.Join(target, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// StartupProfile returns the given profile or the only one, for front ends which can't ask
+func StartupProfile(savDir string, name string) (string, error) {
+	if err := MigrateToProfiles(savDir); err != nil {
+		return "", err
+	}
+	profiles := ListProfiles(savDir)
+	switch {
+	case name != "" && slices.Contains(profiles, name):
+		return name, nil
+	case name != "":
+		return "", NewError("profile.unknown", "Profile", name)
+	case len(profiles) == 1:
+		return profiles[0], nil
+	}
+	return "", NewError("profile.chooseOne", "Profiles", strings.Join(profiles, ", "))
+}
+
+func LoadPreferences(dir string) Preferences {
+	prefs := DefaultPreferences()
+	data, err := os.ReadFile(filepath.Join(dir, preferencesFile))
+	if err != nil {
+		return prefs
+	}
+	json.Unmarshal(data, &prefs)
+	return prefs
+}
+
+func SavePreferences(dir string, prefs Preferences) error {
+	data, err := json.MarshalIndent(prefs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(filepath.Join(dir, preferencesFile), data, 0644)
+}
+
+// ******************************************************
+// OPENING AND CLOSING
+// ******************************************************
+
+// OpenProfile loads statistics, card statistics and preferences of a profile.
+// The profile is usable even if errors are returned.
+func OpenProfile(savDir string, name string, storage string) (*Profile, []error) {
+	dir := ProfileDir(savDir, name)
+	p := &Profile{Name: name, Dir: dir, Prefs: LoadPreferences(dir)}
+
+	errorList := []error{}
+	store, err := OpenStatsStore(dir, storage)
+	p.store = store
+	if err != nil {
+		errorList = append(errorList, err)
+	}
+	if err := p.loadStatistics(); err != nil {
+		errorList = append(errorList, err)
+	}
+	if err := p.loadCardStats(); err != nil {
+		errorList = append(errorList, err)
+	}
+	return p, errorList
+}
+
+// InMemoryProfile counts answers without saving them, e.g. if there is no savDir
+func InMemoryProfile() *Profile {
+	p := &Profile{Prefs: DefaultPreferences()}
+	p.loadStatistics()
+	p.loadCardStats()
+	return p
+}
+
+// AcquireLock returns a LockedError if another instance uses the profile
+func (p *Profile) AcquireLock() error {
+	if p.Dir == "" || p.lock != nil {
+		return nil
+	}
+	lock, err := AcquireInstanceLock(p.Dir)
+	if err != nil {
+		return err
+	}
+	p.lock = lock
+	return nil
+}
+
+// Save writes the changes of this session, a profile which was only read is left untouched
+func (p *Profile) Save() error {
+	if p.Dir == "" || (len(statisticsDelta(p.savedStatistics, p.statistics)) == 0 && len(cardStatsDelta(p.savedCardStats, p.cardStats)) == 0) {
+		return nil
+	}
+	if err := os.MkdirAll(p.Dir, 0755); err != nil {
+		return err
+	}
+	return WithSaveLock(p.Dir, func() error {
+		// the ID is created on the first save, so that a copy of the profile keeps it
+		_, err := ensureDeviceID(p.Dir)
+		return errors.Join(err, p.saveStatistics(), p.saveCardStats())
+	})
+}
+
+// Close saves everything and releases the profile's directory
+func (p *Profile) Close() error {
+	err := p.Save()
+	if p.store != nil {
+		p.store.Close()
+		p.store = nil
+	}
+	p.lock.Release()
+	p.lock = nil
+	return err
+}
+
+// ******************************************************
+// RECORDING ANSWERS
+// ******************************************************
+
+// Record counts an answer in the statistics and appends it to the answer log,
+// the statistics are written by Save
+func (p *Profile) Record(e AnswerEvent) error {
+	p.statistics[e.dayKey()] = e.countIn(p.statistics[e.dayKey()])
+	p.cardStats[e.StatsKey()] = e.countInCard(p.cardStats[e.StatsKey()])
+	if p.Dir == "" {
+		return nil
+	}
+	return AppendEvent(p.Dir, e)
+}
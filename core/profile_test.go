@@ -0,0 +1,39 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestCheckPIN(t *testing.T) {
+	sum := sha256.Sum256([]byte("0011" + "1234"))
+	legacy := "0011$" + hex.EncodeToString(sum[:])
+	hashed := HashPIN("1234")
+	tests := []struct {
+		name     string
+		stored   string
+		pin      string
+		want     bool
+		outdated bool
+	}{
+		{"no PIN", "", "", true, false},
+		{"right PIN", hashed, "1234", true, false},
+		{"wrong PIN", hashed, "1235", false, false},
+		{"old hash, right PIN", legacy, "1234", true, true},
+		{"old hash, wrong PIN", legacy, "4321", false, true},
+		{"broken hash", "pbkdf2-sha256$x$00$00", "1234", false, true},
+	}
+	for _, tt := range tests {
+		prefs := Preferences{PIN: tt.stored}
+		if got := prefs.CheckPIN(tt.pin); got != tt.want {
+			t.Errorf("%s: CheckPIN = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := prefs.OutdatedPIN(); got != tt.outdated {
+			t.Errorf("%s: OutdatedPIN = %v, want %v", tt.name, got, tt.outdated)
+		}
+	}
+	if HashPIN("1234") == hashed {
+		t.Error("two hashes of the same PIN are equal, the salt is missing")
+	}
+}
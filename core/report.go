@@ -0,0 +1,162 @@
+package core
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// A session report is a single HTML file with inline CSS and no scripts, so that it can
+// be handed in, opened in any browser and printed without the app.
+
+//go:embed templates/report.html
+var reportFiles embed.FS
+
+var reportTemplate = template.Must(template.New("report.html").Funcs(template.FuncMap{"t": T, "lang": Language}).ParseFS(reportFiles, "templates/report.html"))
+
+type reportData struct {
+	Title      string
+	Profile    string
+	Pair       string
+	Groups     string
+	Date       string
+	Duration   string
+	Strictness string
+	Answers    []reportAnswer
+	Stats      Stats
+	Rate       string
+}
+
+type reportAnswer struct {
+	Number   int
+	Source   string
+	Target   string
+	Comment  string
+	Input    string
+	Success  SuccessLevel
+	Class    string // "correct", "similar" or "wrong", like on the web pages
+	Feedback string
+	Diff     []DiffPart
+}
+
+// WriteReport writes the answers of the session as an HTML page
+func WriteReport(w io.Writer, conf Config, s *Session) error {
+	if len(s.Answers) == 0 {
+		return NewError("report.empty")
+	}
+	groups := T("report.allGroups")
+	if len(s.Deck.Groups) > 0 {
+		groups = strings.Join(s.Deck.Groups, ", ")
+	}
+	last := s.Answers[len(s.Answers)-1].Event.Time
+	data := reportData{
+		Title:      fmt.Sprintf("%s, %s", conf.GetLangPairAsString(s.Deck.AskedPair()), s.Started.Format(T("format.date"))),
+		Profile:    s.Profile.Name,
+		Pair:       conf.GetLangPairAsString(s.Deck.AskedPair()),
+		Groups:     groups,
+		Date:       fmt.Sprintf("%s - %s", s.Started.Format(T("format.dateTime")), last.Format("15:04")),
+		Duration:   last.Sub(s.Started).Round(time.Second).String(),
+		Strictness: T("strictness." + string(s.Checker.Strictness)),
+		Stats:      s.Stats,
+		Rate:       fmt.Sprintf("%.0f %%", s.Stats.SuccessRate()),
+	}
+	for i, a := range s.Answers {
+		data.Answers = append(data.Answers, reportAnswer{
+			Number:   i + 1,
+			Source:   a.Card.Source,
+			Target:   a.Card.Target,
+			Comment:  a.Card.SourceComment,
+			Input:    a.Event.Input,
+			Success:  a.Event.Success,
+			Class:    successClass(a.Event.Success),
+			Feedback: a.Event.Feedback(),
+			Diff:     DiffAnswer(a.Event.Input, a.Card.Target, s.Checker),
+		})
+	}
+	return reportTemplate.Execute(w, data)
+}
+
+func successClass(success SuccessLevel) string {
+	switch success {
+	case Correct:
+		return "correct"
+	case Similar, Skipped:
+		return "similar"
+	}
+	return "wrong"
+}
+
+// ******************************************************
+// DIFF
+// ******************************************************
+
+type DiffKind int
+
+const (
+	DiffSame    DiffKind = iota
+	DiffMissing          // in the solution, but not in the answer
+	DiffExtra            // in the answer, but not in the solution
+)
+
+type DiffPart struct {
+	Kind DiffKind
+	Text string
+}
+
+func (p DiffPart) Missing() bool { return p.Kind == DiffMissing }
+func (p DiffPart) Extra() bool   { return p.Kind == DiffExtra }
+
+// DiffAnswer compares an answer with the solution letter by letter,
+// using the longest common subsequence. Letters are the same if the checker
+// doesn't tell them apart, e.g. "Chat" and "chat" unless it is strict.
+func DiffAnswer(input string, solution string, c Checker) []DiffPart {
+	a, b := []rune(input), []rune(solution)
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if c.sameLetter(a[i], b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	parts := []DiffPart{}
+	add := func(kind DiffKind, r rune) {
+		if n := len(parts); n > 0 && parts[n-1].Kind == kind {
+			parts[n-1].Text += string(r)
+			return
+		}
+		parts = append(parts, DiffPart{Kind: kind, Text: string(r)})
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case c.sameLetter(a[i], b[j]):
+			add(DiffSame, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(DiffExtra, a[i])
+			i++
+		default:
+			add(DiffMissing, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		add(DiffExtra, a[i])
+	}
+	for ; j < len(b); j++ {
+		add(DiffMissing, b[j])
+	}
+	return parts
+}
@@ -0,0 +1,32 @@
+package core
+
+import "testing"
+
+func TestDiffAnswer(t *testing.T) {
+	tests := []struct {
+		strictness Strictness
+		input      string
+		solution   string
+		want       []DiffPart
+	}{
+		{StrictnessNormal, "Chat", "chat", []DiffPart{{DiffSame, "Chat"}}},
+		{StrictnessStrict, "Chat", "chat", []DiffPart{{DiffExtra, "C"}, {DiffMissing, "c"}, {DiffSame, "hat"}}},
+		{StrictnessNormal, "cafe", "café", []DiffPart{{DiffSame, "caf"}, {DiffExtra, "e"}, {DiffMissing, "é"}}},
+		{StrictnessLenient, "cafe", "café", []DiffPart{{DiffSame, "cafe"}}},
+		{StrictnessNormal, "chen", "chien", []DiffPart{{DiffSame, "ch"}, {DiffMissing, "i"}, {DiffSame, "en"}}},
+		{StrictnessNormal, "", "chat", []DiffPart{{DiffMissing, "chat"}}},
+	}
+	for _, tt := range tests {
+		got := DiffAnswer(tt.input, tt.solution, Checker{Strictness: tt.strictness})
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: DiffAnswer(%q, %q) = %v, want %v", tt.strictness, tt.input, tt.solution, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: DiffAnswer(%q, %q) = %v, want %v", tt.strictness, tt.input, tt.solution, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Package core contains everything of fancyCards which doesn't depend on a user interface:
+// the configuration, reading decks, checking answers, profiles and their statistics.
+// A front end opens a Profile, loads a Deck and practices it in a Session.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Session asks the cards of a deck in random order and records the answers in the profile
+type Session struct {
+	Profile *Profile
+	Deck    Deck
+	Checker Checker
+	Stats   Stats // answers of this session
+	Started time.Time
+	Answers []SessionAnswer // in the order they were given, for the report
+	Limit   SessionLimit
+
+	rando   Randomizer
+	current Card
+	shownAt time.Time // to measure the response time
+	hints   int       // letters of the solution shown for the current card
+}
+
+// SessionAnswer is an answer together with the card it belongs to
+type SessionAnswer struct {
+	Card  Card
+	Event AnswerEvent
+}
+
+// SessionLimit ends a session after a number of cards or minutes, whichever comes first
+type SessionLimit struct {
+	Cards    int           // 0: no limit
+	Duration time.Duration // 0: no limit
+}
+
+// NewSession uses the preferences of the profile to pick and check the cards
+// and to limit the length of the session
+func NewSession(p *Profile, deck Deck) *Session {
+	s := &Session{Profile: p, Deck: deck, Checker: Checker{Strictness: p.Prefs.Strictness}, Started: time.Now()}
+	s.Limit = SessionLimit{Cards: p.Prefs.SessionCards, Duration: time.Duration(p.Prefs.SessionMinutes) * time.Minute}
+	if p.Prefs.PracticeMode != PracticeHardest {
+		s.rando = NewRandomizer(deck.Cards)
+		return s
+	}
+	weights := make([]int, len(deck.Cards))
+	for i, wc := range deck.Cards {
+		// a card never answered correctly is asked 5 times as often as an easy one
+		weights[i] = 1 + int(4*p.LoadCardStats(wc).Difficulty()+0.5)
+	}
+	s.rando = NewWeightedRandomizer(deck.Cards, weights)
+	return s
+}
+
+// Next picks the next card to ask
+func (s *Session) Next() Card {
+	s.current = s.rando.Next()
+	s.shownAt = time.Now()
+	s.hints = 0
+	return s.current
+}
+
+func (s *Session) Current() Card {
+	return s.current
+}
+
+// Submit checks the answer to the current card, records it and saves the profile.
+// If the deck uses shortcuts, the ones of the answer language are replaced first,
+// e.g. "\l/" by "ł". The event is valid even if recording or saving failed.
+func (s *Session) Submit(input string) (AnswerEvent, error) {
+	if s.Deck.Shortcuts {
+		input = ExpandShortcuts(input, s.Deck.Characters)
+	}
+	now := time.Now()
+	r := s.Checker.Check(input, s.current)
+	e := AnswerEvent{
+		ID:         NewEventID(),
+		Time:       now,
+		LangPair:   s.Deck.AskedPair().ToString(),
+		Reverse:    s.current.Reversed,
+		CardID:     s.current.ID,
+		Input:      input,
+		Success:    r.Success,
+		Mistake:    r.Mistake,
+		ResponseMs: now.Sub(s.shownAt).Milliseconds(),
+	}
+	s.Stats = e.countIn(s.Stats)
+	s.Answers = append(s.Answers, SessionAnswer{Card: s.current, Event: e})
+	err := s.Profile.Record(e)
+	return e, errors.Join(err, s.Profile.Save())
+}
+
+// ******************************************************
+// LENGTH
+// ******************************************************
+
+func (s *Session) Elapsed() time.Duration {
+	return time.Since(s.Started)
+}
+
+// Done tells if the session reached its limit, the current card may still be answered.
+// Front ends which wait for an answer should end the session themselves when Limit.Duration
+// is over, e.g. with a timer.
+func (s *Session) Done() bool {
+	return (s.Limit.Cards > 0 && len(s.Answers) >= s.Limit.Cards) ||
+		(s.Limit.Duration > 0 && s.Elapsed() >= s.Limit.Duration)
+}
+
+// TimeLeft is the time until the session reaches its minute limit, 0 without one
+func (s *Session) TimeLeft() time.Duration {
+	if s.Limit.Duration == 0 {
+		return 0
+	}
+	return max(s.Limit.Duration-s.Elapsed(), 0)
+}
+
+// LimitText describes how far the session is, e.g. "Card 3 of 10 · 4:05 left"
+func (s *Session) LimitText() string {
+	texts := []string{}
+	if s.Limit.Cards > 0 {
+		texts = append(texts, T("session.cards", "Count", len(s.Answers), "Cards", s.Limit.Cards))
+	}
+	if s.Limit.Duration > 0 {
+		texts = append(texts, T("session.timeLeft", "Time", FormatDuration(s.TimeLeft())))
+	}
+	return strings.Join(texts, " · ")
+}
+
+// FormatDuration shows minutes and seconds, e.g. "4:05"
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// Progress is the part of the session which is over, from 0 to 1, by cards or time
+// whichever is further. It is 0 for a session without limit.
+func (s *Session) Progress() float64 {
+	progress := 0.0
+	if s.Limit.Cards > 0 {
+		progress = float64(len(s.Answers)) / float64(s.Limit.Cards)
+	}
+	if s.Limit.Duration > 0 {
+		progress = max(progress, float64(s.Elapsed())/float64(s.Limit.Duration))
+	}
+	return min(progress, 1)
+}
+
+// FailedCards returns the cards with an answer which was not correct, in the order they were asked
+func (s *Session) FailedCards() []Card {
+	failed := []Card{}
+	for _, a := range s.Answers {
+		if a.Event.Success != Correct && !slices.ContainsFunc(failed, func(wc Card) bool { return wc.StatsKey() == a.Card.StatsKey() }) {
+			failed = append(failed, a.Card)
+		}
+	}
+	return failed
+}
+
+// RetryFailed starts a session which asks each failed card once
+func (s *Session) RetryFailed() *Session {
+	deck := s.Deck
+	deck.Cards = s.FailedCards()
+	retry := NewSession(s.Profile, deck)
+	retry.rando = NewShuffledRandomizer(deck.Cards)
+	retry.Limit = SessionLimit{Cards: len(deck.Cards)}
+	return retry
+}
+
+// ******************************************************
+// CORRECTIONS AND HINTS
+// ******************************************************
+
+// CanMarkCorrect tells if the last answer was rated wrong or similar and can be marked as correct
+func (s *Session) CanMarkCorrect() bool {
+	if len(s.Answers) == 0 {
+		return false
+	}
+	success := s.Answers[len(s.Answers)-1].Event.Success
+	return success == Wrong || success == Similar
+}
+
+// MarkCorrect rates the last answer as correct, e.g. a synonym which is not in the deck.
+// The correction is recorded like an answer, see AnswerEvent.Corrects.
+func (s *Session) MarkCorrect() (AnswerEvent, error) {
+	if !s.CanMarkCorrect() {
+		return AnswerEvent{}, NewError("session.cannotMarkCorrect")
+	}
+	last := &s.Answers[len(s.Answers)-1]
+	e := last.Event
+	e.ID = NewEventID()
+	e.Time = time.Now()
+	e.Corrects = last.Event.ID
+	e.Corrected = &Result{Success: last.Event.Success, Mistake: last.Event.Mistake}
+	e.Success = Correct
+	e.Mistake = NoMistakeKind
+	s.Stats = e.countIn(s.Stats)
+	last.Event = e
+	err := s.Profile.Record(e)
+	return e, errors.Join(err, s.Profile.Save())
+}
+
+// Hint shows one more letter of the solution of the current card each time,
+// the other letters are replaced by "_"
+func (s *Session) Hint() string {
+	s.hints++
+	hint := []rune{}
+	shown := 0
+	for _, r := range s.current.Target {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			hint = append(hint, r)
+		case shown < s.hints:
+			hint = append(hint, r)
+			shown++
+		default:
+			hint = append(hint, '_')
+		}
+	}
+	return string(hint)
+}
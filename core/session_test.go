@@ -0,0 +1,59 @@
+package core
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	lp := LangPair{Source: "de", Target: "fr"}
+	card := Card{ID: DeriveCardID(lp, "Katze", "le chat"), LangPair: lp, FileName: "tiere.csv", Source: "Katze", Target: "le chat"}
+	p := InMemoryProfile()
+	s := NewSession(p, Deck{LangPair: lp, Cards: []Card{card}})
+
+	tests := []struct {
+		input string
+		want  SuccessLevel
+	}{
+		{"le chat", Correct},
+		{"chat", Similar},
+		{"le chien", Wrong},
+		{"", Skipped},
+	}
+	for _, tt := range tests {
+		if got := s.Next(); got.ID != card.ID {
+			t.Fatalf("Next() = %+v, want %+v", got, card)
+		}
+		e, err := s.Submit(tt.input)
+		if err != nil || e.Success != tt.want || e.CardID != card.ID || e.LangPair != "de_fr" {
+			t.Errorf("Submit(%q) = %+v, %v, want %v", tt.input, e, err, tt.want)
+		}
+	}
+	want := Stats{Count: 4, Successes: 1, Similar: 1, Mistakes: 1, Skipped: 1}
+	if s.Stats != want {
+		t.Errorf("session stats = %+v, want %+v", s.Stats, want)
+	}
+	if cs := p.LoadCardStats(card); cs.Attempts != 4 {
+		t.Errorf("card stats = %+v, want 4 answers", cs)
+	}
+}
+
+// the core has to work without a window, so it mustn't import fyne
+func TestNoUserInterface(t *testing.T) {
+	files, _ := filepath.Glob("*.go")
+	fset := token.NewFileSet()
+	for _, name := range files {
+		f, err := parser.ParseFile(fset, name, nil, parser.ImportsOnly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, imp := range f.Imports {
+			if strings.Contains(imp.Path.Value, "fyne.io") {
+				t.Errorf("%s imports %s", name, imp.Path.Value)
+			}
+		}
+	}
+}
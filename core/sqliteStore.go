@@ -0,0 +1,257 @@
+//go:build !js
+
+package core
+
+import (
+	"database/sql"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore keeps statistics and card statistics in fancyCards.db in the profile's directory.
+// Only changed rows are written, concurrent instances are handled by SQLite's locking.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS statistics (
+	key       TEXT PRIMARY KEY,
+	count     INTEGER NOT NULL DEFAULT 0,
+	successes INTEGER NOT NULL DEFAULT 0,
+	mistakes  INTEGER NOT NULL DEFAULT 0,
+	similar   INTEGER NOT NULL DEFAULT 0,
+	skipped   INTEGER NOT NULL DEFAULT 0,
+	accents   INTEGER NOT NULL DEFAULT 0,
+	typos     INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS card_stats (
+	key       TEXT PRIMARY KEY,
+	attempts  INTEGER NOT NULL DEFAULT 0,
+	correct   INTEGER NOT NULL DEFAULT 0,
+	similar   INTEGER NOT NULL DEFAULT 0,
+	wrong     INTEGER NOT NULL DEFAULT 0,
+	skipped   INTEGER NOT NULL DEFAULT 0,
+	last_seen TEXT NOT NULL DEFAULT '',
+	streak    INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+// last_seen is stored as UTC RFC 3339 with fixed width, so that it can be compared as text
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+func OpenSQLiteStore(dir string) (StatsStore, error) {
+	path := filepath.Join(dir, sqliteFileName)
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, NewError("storage.dbFailed", "File", path, "Err", err)
+	}
+	s := &SQLiteStore{db: db}
+
+	var migrated string
+	err = db.QueryRow(`SELECT value FROM meta WHERE key = 'json_migrated'`).Scan(&migrated)
+	if err == sql.ErrNoRows {
+		err = s.migrateJSONFiles(dir)
+	}
+	if err == nil {
+		// also renames the files if that failed after the import
+		err = renameMigratedJSONFiles(dir)
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// migrateJSONFiles imports existing JSON statistics once. The import and the mark in meta
+// are one transaction, so a failure in between doesn't import the files twice.
+func (s *SQLiteStore) migrateJSONFiles(dir string) error {
+	st, cs, err := readJSONFiles(dir)
+	if err != nil {
+		return err
+	}
+	err = s.inTransaction(func(tx *sql.Tx) error {
+		if err := addStatisticsTx(tx, st); err != nil {
+			return err
+		}
+		if err := addCardStatsTx(tx, cs); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`INSERT INTO meta (key, value) VALUES ('json_migrated', ?)`, time.Now().Format(time.RFC3339))
+		return err
+	})
+	if err != nil {
+		return NewError("storage.migrateFailed", "File", dir, "Err", err)
+	}
+	return nil
+}
+
+// readSQLiteFile reads the database of another profile without changing it. migrated is
+// false if the JSON files in dir weren't imported into it yet.
+func readSQLiteFile(dir string) (st map[string]Stats, cs map[string]CardStats, migrated bool, err error) {
+	path := filepath.Join(dir, sqliteFileName)
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, nil, false, err
+	}
+	defer db.Close()
+	s := &SQLiteStore{db: db}
+	if st, err = s.LoadStatistics(); err != nil {
+		return nil, nil, false, NewError("storage.dbFailed", "File", path, "Err", err)
+	}
+	if cs, err = s.LoadCardStats(); err != nil {
+		return nil, nil, false, NewError("storage.dbFailed", "File", path, "Err", err)
+	}
+	var value string
+	err = db.QueryRow(`SELECT value FROM meta WHERE key = 'json_migrated'`).Scan(&value)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, nil, false, NewError("storage.dbFailed", "File", path, "Err", err)
+	}
+	return st, cs, err == nil, nil
+}
+
+func (s *SQLiteStore) LoadStatistics() (map[string]Stats, error) {
+	rows, err := s.db.Query(`SELECT key, count, successes, mistakes, similar, skipped, accents, typos FROM statistics`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	st := map[string]Stats{}
+	for rows.Next() {
+		var key string
+		var v Stats
+		if err := rows.Scan(&key, &v.Count, &v.Successes, &v.Mistakes, &v.Similar, &v.Skipped, &v.Accents, &v.Typos); err != nil {
+			return nil, err
+		}
+		st[key] = v
+	}
+	return st, rows.Err()
+}
+
+func (s *SQLiteStore) AddStatistics(delta map[string]Stats) (map[string]Stats, error) {
+	if err := s.inTransaction(func(tx *sql.Tx) error { return addStatisticsTx(tx, delta) }); err != nil {
+		return nil, err
+	}
+	return s.LoadStatistics()
+}
+
+func addStatisticsTx(tx *sql.Tx, delta map[string]Stats) error {
+	for key, d := range delta {
+		_, err := tx.Exec(`INSERT INTO statistics (key, count, successes, mistakes, similar, skipped, accents, typos)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (key) DO UPDATE SET
+				count = count + excluded.count,
+				successes = successes + excluded.successes,
+				mistakes = mistakes + excluded.mistakes,
+				similar = similar + excluded.similar,
+				skipped = skipped + excluded.skipped,
+				accents = accents + excluded.accents,
+				typos = typos + excluded.typos`,
+			key, d.Count, d.Successes, d.Mistakes, d.Similar, d.Skipped, d.Accents, d.Typos)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec(`DELETE FROM statistics WHERE count = 0 AND successes = 0 AND mistakes = 0
+		AND similar = 0 AND skipped = 0 AND accents = 0 AND typos = 0`)
+	return err
+}
+
+func (s *SQLiteStore) ReplaceStatistics(st map[string]Stats) error {
+	return s.inTransaction(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM statistics`); err != nil {
+			return err
+		}
+		for key, v := range st {
+			_, err := tx.Exec(`INSERT INTO statistics (key, count, successes, mistakes, similar, skipped, accents, typos)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				key, v.Count, v.Successes, v.Mistakes, v.Similar, v.Skipped, v.Accents, v.Typos)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLiteStore) LoadCardStats() (map[string]CardStats, error) {
+	rows, err := s.db.Query(`SELECT key, attempts, correct, similar, wrong, skipped, last_seen, streak FROM card_stats`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cs := map[string]CardStats{}
+	for rows.Next() {
+		var key, lastSeen string
+		var v CardStats
+		if err := rows.Scan(&key, &v.Attempts, &v.Correct, &v.Similar, &v.Wrong, &v.Skipped, &lastSeen, &v.Streak); err != nil {
+			return nil, err
+		}
+		if lastSeen != "" {
+			v.LastSeen, _ = time.Parse(sqliteTimeLayout, lastSeen)
+			v.LastSeen = v.LastSeen.Local()
+		}
+		cs[key] = v
+	}
+	return cs, rows.Err()
+}
+
+func (s *SQLiteStore) AddCardStats(delta map[string]CardStats) (map[string]CardStats, error) {
+	if err := s.inTransaction(func(tx *sql.Tx) error { return addCardStatsTx(tx, delta) }); err != nil {
+		return nil, err
+	}
+	return s.LoadCardStats()
+}
+
+func addCardStatsTx(tx *sql.Tx, delta map[string]CardStats) error {
+	for key, d := range delta {
+		lastSeen := ""
+		if !d.LastSeen.IsZero() {
+			lastSeen = d.LastSeen.UTC().Format(sqliteTimeLayout)
+		}
+		_, err := tx.Exec(`INSERT INTO card_stats (key, attempts, correct, similar, wrong, skipped, last_seen, streak)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (key) DO UPDATE SET
+				attempts = attempts + excluded.attempts,
+				correct = correct + excluded.correct,
+				similar = similar + excluded.similar,
+				wrong = wrong + excluded.wrong,
+				skipped = skipped + excluded.skipped,
+				streak = CASE WHEN excluded.last_seen > last_seen THEN excluded.streak ELSE streak END,
+				last_seen = MAX(last_seen, excluded.last_seen)`,
+			key, d.Attempts, d.Correct, d.Similar, d.Wrong, d.Skipped, lastSeen, d.Streak)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) inTransaction(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
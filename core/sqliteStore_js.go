@@ -0,0 +1,10 @@
+package core
+
+// modernc.org/sqlite doesn't support the browser, the web build only offers the JSON files
+func OpenSQLiteStore(dir string) (StatsStore, error) {
+	return nil, NewError("storage.sqliteUnsupported")
+}
+
+func readSQLiteFile(dir string) (map[string]Stats, map[string]CardStats, bool, error) {
+	return nil, nil, false, NewError("storage.sqliteUnsupported")
+}
@@ -0,0 +1,305 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Successes only counts correct answers. Similar and skipped answers have their own counters,
+// accent and typo mistakes are additionally counted in their buckets.
+type Stats struct {
+	Count     int `json:"count"`
+	Successes int `json:"successes"`
+	Mistakes  int `json:"mistakes"`
+	Similar   int `json:"similar"`
+	Skipped   int `json:"skipped"`
+	Accents   int `json:"accents,omitempty"`
+	Typos     int `json:"typos,omitempty"`
+}
+
+const DayLayout = "2006-01-02"
+
+func (s Stats) Add(o Stats) Stats {
+	s.Count += o.Count
+	s.Successes += o.Successes
+	s.Mistakes += o.Mistakes
+	s.Similar += o.Similar
+	s.Skipped += o.Skipped
+	s.Accents += o.Accents
+	s.Typos += o.Typos
+	return s
+}
+
+func (s Stats) Sub(o Stats) Stats {
+	s.Count -= o.Count
+	s.Successes -= o.Successes
+	s.Mistakes -= o.Mistakes
+	s.Similar -= o.Similar
+	s.Skipped -= o.Skipped
+	s.Accents -= o.Accents
+	s.Typos -= o.Typos
+	return s
+}
+
+// SuccessRate in percent of all answered (not skipped) cards
+func (s Stats) SuccessRate() float64 {
+	answered := s.Count - s.Skipped
+	if answered <= 0 {
+		return 0
+	}
+	return 100 * float64(s.Successes) / float64(answered)
+}
+
+type StatPeriod struct {
+	Label string
+	Start time.Time
+	Stats Stats
+}
+
+// statistics are stored per language pair and day, e.g. "de_fr_2025-03-17"
+func StatsKey(lp LangPair, day time.Time) string {
+	return lp.ToString() + "_" + day.Format(DayLayout)
+}
+
+// SplitStatsKey returns the language pair and the date part of a statistics key
+func SplitStatsKey(key string) (string, string) {
+	pos := strings.LastIndex(key, "_")
+	if pos < 0 {
+		return "", key
+	}
+	return key[:pos], key[pos+1:]
+}
+
+// ******************************************************
+// READ/WRITE BASICS
+// ******************************************************
+
+func (s Stats) Record(success SuccessLevel, mistake MistakeKind) Stats {
+	s.Count++
+	switch success {
+	case Wrong:
+		s.Mistakes++
+	case Similar:
+		s.Similar++
+	case Correct:
+		s.Successes++
+	case Skipped:
+		s.Skipped++
+	}
+	switch mistake {
+	case AccentMistake:
+		s.Accents++
+	case TypoMistake:
+		s.Typos++
+	}
+	return s
+}
+
+func (p *Profile) LoadStats(lp LangPair, day time.Time) Stats {
+	return p.statistics[StatsKey(lp, day)]
+}
+
+// ******************************************************
+// LISTING
+// ******************************************************
+
+// MonthlyStats sums up the daily statistics of a language pair per month, keyed "<month>/<year>"
+func (p *Profile) MonthlyStats(lp LangPair) map[string]Stats {
+	mp := map[string]Stats{}
+	for day, value := range p.DailyStats(lp) {
+		d, err := time.Parse(DayLayout, day)
+		if err != nil {
+			continue
+		}
+		outkey := fmt.Sprintf("%d/%d", int(d.Month()), d.Year())
+		mp[outkey] = mp[outkey].Add(value)
+	}
+	return mp
+}
+
+// DailyStats returns the statistics of a language pair keyed by day
+func (p *Profile) DailyStats(lp LangPair) map[string]Stats {
+	return p.dailyStats(func(pair string) bool { return pair == lp.ToString() })
+}
+
+// AllDailyStats returns the statistics of all language pairs keyed by day
+func (p *Profile) AllDailyStats() map[string]Stats {
+	return p.dailyStats(func(pair string) bool { return true })
+}
+
+func (p *Profile) dailyStats(match func(pair string) bool) map[string]Stats {
+	mp := map[string]Stats{}
+	for key, value := range p.statistics {
+		pair, day := SplitStatsKey(key)
+		if !match(pair) {
+			continue
+		}
+		mp[day] = mp[day].Add(value)
+	}
+	return mp
+}
+
+// StatsSeries sums up daily statistics per day or per month in chronological order,
+// including periods without practice. A zero from date starts at the first day with statistics.
+func StatsSeries(days map[string]Stats, from time.Time, to time.Time, monthly bool) []StatPeriod {
+	from, _ = time.Parse(DayLayout, from.Format(DayLayout))
+	to, _ = time.Parse(DayLayout, to.Format(DayLayout))
+	if from.Year() <= 1 {
+		from = to
+		for day := range days {
+			d, err := time.Parse(DayLayout, day)
+			if err == nil && d.Before(from) {
+				from = d
+			}
+		}
+	}
+
+	series := []StatPeriod{}
+	if monthly {
+		from = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+		for m := from; !m.After(to); m = m.AddDate(0, 1, 0) {
+			series = append(series, StatPeriod{Label: fmt.Sprintf("%d/%d", int(m.Month()), m.Year()), Start: m})
+		}
+	} else {
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			series = append(series, StatPeriod{Label: d.Format(T("format.date")), Start: d})
+		}
+	}
+
+	for day, value := range days {
+		d, err := time.Parse(DayLayout, day)
+		if err != nil || d.After(to) || len(series) == 0 || d.Before(series[0].Start) {
+			continue
+		}
+		for i := len(series) - 1; i >= 0; i-- {
+			if !d.Before(series[i].Start) {
+				series[i].Stats = series[i].Stats.Add(value)
+				break
+			}
+		}
+	}
+	return series
+}
+
+// CalculateStreaks returns the number of consecutive days with practice up to today
+// (or yesterday, if there was no practice yet today) and the longest streak ever
+func CalculateStreaks(days map[string]Stats, today time.Time) (int, int) {
+	practiced := []time.Time{}
+	for day, s := range days {
+		d, err := time.Parse(DayLayout, day)
+		if err != nil || s.Count == 0 {
+			continue
+		}
+		practiced = append(practiced, d)
+	}
+	slices.SortFunc(practiced, func(x, y time.Time) int { return x.Compare(y) })
+
+	longest, run := 0, 0
+	for i, d := range practiced {
+		if i > 0 && practiced[i-1].AddDate(0, 0, 1).Equal(d) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	current := 0
+	day, _ := time.Parse(DayLayout, today.Format(DayLayout))
+	if days[day.Format(DayLayout)].Count == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day.Format(DayLayout)].Count > 0 {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return current, longest
+}
+
+// migrateStats moves statistics from the old "<pair>_<month>_<year>" keys
+// to the first day of the respective month.
+// Old entries counted similar answers as successes and skipped answers nowhere:
+// the skipped ones are restored from the difference, the similar ones stay successes.
+func migrateStats(st map[string]Stats) map[string]Stats {
+	migrated := map[string]Stats{}
+	for key, value := range st {
+		if missing := value.Count - value.Successes - value.Mistakes - value.Similar - value.Skipped; missing > 0 {
+			value.Skipped += missing
+		}
+
+		parts := strings.Split(key, "_")
+		if len(parts) == 4 {
+			month, errM := strconv.Atoi(parts[2])
+			year, errY := strconv.Atoi(parts[3])
+			if errM == nil && errY == nil {
+				lp := LangPair{Source: parts[0], Target: parts[1]}
+				key = StatsKey(lp, time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local))
+			}
+		}
+		migrated[key] = migrated[key].Add(value)
+	}
+	return migrated
+}
+
+// ******************************************************
+// HANDLING FILES
+// ******************************************************
+
+// statisticsDelta returns the changes of this session (memory - baseline)
+func statisticsDelta(baseline map[string]Stats, memory map[string]Stats) map[string]Stats {
+	delta := map[string]Stats{}
+	keys := maps.Clone(memory)
+	maps.Copy(keys, baseline)
+	for key := range keys {
+		if d := memory[key].Sub(baseline[key]); d != (Stats{}) {
+			delta[key] = d
+		}
+	}
+	return delta
+}
+
+// addStatistics returns the sum of both, without empty entries
+func addStatistics(st map[string]Stats, delta map[string]Stats) map[string]Stats {
+	sum := maps.Clone(st)
+	for key, d := range delta {
+		sum[key] = sum[key].Add(d)
+		if sum[key] == (Stats{}) {
+			delete(sum, key)
+		}
+	}
+	return sum
+}
+
+// saveStatistics only stores the changes of this session, so that statistics
+// saved by another instance in the meantime are kept
+func (p *Profile) saveStatistics() error {
+	if p.store == nil {
+		return nil
+	}
+	saved, err := p.store.AddStatistics(statisticsDelta(p.savedStatistics, p.statistics))
+	if err != nil {
+		return err
+	}
+	p.statistics = saved
+	p.savedStatistics = maps.Clone(saved)
+	return nil
+}
+
+func (p *Profile) loadStatistics() error {
+	p.statistics = map[string]Stats{}
+	p.savedStatistics = map[string]Stats{}
+	if p.store == nil {
+		return nil
+	}
+	// the store may return statistics and an error, e.g. if they were recovered from a backup
+	st, err := p.store.LoadStatistics()
+	if st != nil {
+		p.statistics = st
+		p.savedStatistics = maps.Clone(st)
+	}
+	return err
+}
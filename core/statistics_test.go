@@ -0,0 +1,52 @@
+package core
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestMigrateStats(t *testing.T) {
+	tests := []struct {
+		name string
+		old  map[string]Stats
+		want map[string]Stats
+	}{
+		{
+			name: "monthly key",
+			old:  map[string]Stats{"de_fr_3_2024": {Count: 4, Successes: 3, Mistakes: 1}},
+			want: map[string]Stats{"de_fr_2024-03-01": {Count: 4, Successes: 3, Mistakes: 1}},
+		},
+		{
+			name: "skipped answers are restored",
+			old:  map[string]Stats{"de_fr_3_2024": {Count: 5, Successes: 3, Mistakes: 1}},
+			want: map[string]Stats{"de_fr_2024-03-01": {Count: 5, Successes: 3, Mistakes: 1, Skipped: 1}},
+		},
+		{
+			name: "daily keys stay",
+			old:  map[string]Stats{"de_fr_2024-03-17": {Count: 1, Successes: 1}},
+			want: map[string]Stats{"de_fr_2024-03-17": {Count: 1, Successes: 1}},
+		},
+	}
+	for _, tt := range tests {
+		if got := migrateStats(tt.old); !maps.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStatisticsDelta(t *testing.T) {
+	saved := map[string]Stats{"a": {Count: 2, Successes: 2}, "b": {Count: 1, Mistakes: 1}}
+	memory := map[string]Stats{"a": {Count: 3, Successes: 2, Mistakes: 1}, "b": {Count: 1, Mistakes: 1}, "c": {Count: 1, Skipped: 1}}
+	delta := statisticsDelta(saved, memory)
+	want := map[string]Stats{"a": {Count: 1, Mistakes: 1}, "c": {Count: 1, Skipped: 1}}
+	if !maps.Equal(delta, want) {
+		t.Errorf("delta = %v, want %v", delta, want)
+	}
+	// another instance saved in the meantime, its answers are kept
+	disk := map[string]Stats{"a": {Count: 4, Successes: 4}, "b": {Count: 1, Mistakes: 1}}
+	sum := addStatistics(disk, delta)
+	wantSum := map[string]Stats{"a": {Count: 5, Successes: 4, Mistakes: 1}, "b": {Count: 1, Mistakes: 1}, "c": {Count: 1, Skipped: 1}}
+	if !maps.Equal(sum, wantSum) {
+		t.Errorf("sum = %v, want %v", sum, wantSum)
+	}
+}
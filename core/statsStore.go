@@ -0,0 +1,232 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StatsStore persists the aggregated statistics and the per-card statistics.
+// Saving only passes the changes of the session: other instances may have saved
+// in the meantime, a store adds the changes to what it has and returns the result.
+type StatsStore interface {
+	// LoadStatistics may return statistics together with an error, e.g. if they were recovered from a backup
+	LoadStatistics() (map[string]Stats, error)
+	AddStatistics(delta map[string]Stats) (map[string]Stats, error)
+	ReplaceStatistics(st map[string]Stats) error
+	LoadCardStats() (map[string]CardStats, error)
+	AddCardStats(delta map[string]CardStats) (map[string]CardStats, error)
+	Close() error
+}
+
+const (
+	storageJSON    = "json"
+	storageSQLite  = "sqlite"
+	sqliteFileName = "fancyCards.db"
+)
+
+// OpenStatsStore opens the storage selected in the INI in the profile's directory
+func OpenStatsStore(dir string, storage string) (StatsStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	switch storage {
+	case "", storageJSON:
+		return NewJSONStore(dir), nil
+	case storageSQLite:
+		return OpenSQLiteStore(dir)
+	}
+	return nil, NewError("storage.unknown", "Storage", storage, "JSON", storageJSON, "SQLite", storageSQLite)
+}
+
+// ******************************************************
+// JSON FILES
+// ******************************************************
+
+// JSONStore keeps the statistics in statistik.json and the card statistics in karten_statistik.json.
+// Callers must keep other instances from saving at the same time, see WithSaveLock.
+type JSONStore struct {
+	savFile       string
+	backupFile    string
+	cardStatsFile string
+	// as last read or written, if the file can't be read when saving
+	statistics map[string]Stats
+	cardStats  map[string]CardStats
+}
+
+func NewJSONStore(dir string) *JSONStore {
+	return &JSONStore{
+		savFile:       filepath.Join(dir, "statistik.json"),
+		backupFile:    filepath.Join(dir, "_statistik.json"),
+		cardStatsFile: filepath.Join(dir, "karten_statistik.json"),
+		statistics:    map[string]Stats{},
+		cardStats:     map[string]CardStats{},
+	}
+}
+
+// LoadStatistics reads statistik.json. Before it is replaced, a readable statistik.json
+// is copied to _statistik.json, which is used for recovery:
+//   - statistik.json is missing: the backup is loaded, if there is one
+//   - statistik.json is damaged: it is kept as statistik.json.defekt-<time> and the backup is loaded
+//
+// In both cases an error is returned to inform the user.
+func (j *JSONStore) LoadStatistics() (map[string]Stats, error) {
+	st, err := readStatisticsFile(j.savFile)
+	if err == nil {
+		j.statistics = st
+		return st, nil
+	}
+
+	missing := errors.Is(err, fs.ErrNotExist)
+	if !missing {
+		damagedFile := fmt.Sprintf("%s.defekt-%s", j.savFile, time.Now().Format("20060102-150405"))
+		os.Rename(j.savFile, damagedFile)
+	}
+
+	backup, backupErr := readStatisticsFile(j.backupFile)
+	switch {
+	case backupErr == nil && missing:
+		j.statistics = backup
+		return backup, NewError("storage.restoredMissing", "File", j.savFile, "Backup", j.backupFile)
+	case backupErr == nil:
+		j.statistics = backup
+		return backup, NewError("storage.restoredDamaged", "File", j.savFile, "Err", err, "Backup", j.backupFile)
+	case missing:
+		return map[string]Stats{}, nil // first start
+	}
+	return map[string]Stats{}, NewError("storage.lost", "File", j.savFile, "Err", err)
+}
+
+func (j *JSONStore) AddStatistics(delta map[string]Stats) (map[string]Stats, error) {
+	disk, err := readStatisticsFile(j.savFile)
+	if err != nil {
+		disk = j.statistics // nothing (readable) to add to
+	}
+	st := addStatistics(disk, delta)
+	return st, j.ReplaceStatistics(st)
+}
+
+// ReplaceStatistics is called with the save lock held, like the other saving methods
+func (j *JSONStore) ReplaceStatistics(st map[string]Stats) error {
+	RemoveStaleTempFiles(j.savFile, saveLockStaleTime)
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := readStatisticsFile(j.savFile); err == nil {
+		if old, err := os.ReadFile(j.savFile); err == nil {
+			WriteFileAtomic(j.backupFile, old, 0644)
+		}
+	}
+	if err := WriteFileAtomic(j.savFile, data, 0644); err != nil {
+		return err
+	}
+	j.statistics = st
+	return nil
+}
+
+func (j *JSONStore) LoadCardStats() (map[string]CardStats, error) {
+	cs, err := readCardStatisticsFile(j.cardStatsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]CardStats{}, nil
+	}
+	if err != nil {
+		return map[string]CardStats{}, err
+	}
+	j.cardStats = cs
+	return cs, nil
+}
+
+func (j *JSONStore) AddCardStats(delta map[string]CardStats) (map[string]CardStats, error) {
+	RemoveStaleTempFiles(j.cardStatsFile, saveLockStaleTime)
+	disk, err := readCardStatisticsFile(j.cardStatsFile)
+	if err != nil {
+		disk = j.cardStats
+	}
+	cs := addCardStats(disk, delta)
+	data, err := json.MarshalIndent(cs, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := WriteFileAtomic(j.cardStatsFile, data, 0644); err != nil {
+		return nil, err
+	}
+	j.cardStats = cs
+	return cs, nil
+}
+
+func (j *JSONStore) Close() error {
+	return nil
+}
+
+func readStatisticsFile(path string) (map[string]Stats, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var st map[string]Stats
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, err
+	}
+	if st == nil {
+		st = map[string]Stats{}
+	}
+	return migrateStats(st), nil
+}
+
+func readCardStatisticsFile(path string) (map[string]CardStats, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cs map[string]CardStats
+	if err := json.Unmarshal(data, &cs); err != nil {
+		return nil, err
+	}
+	if cs == nil {
+		cs = map[string]CardStats{}
+	}
+	return cs, nil
+}
+
+// ******************************************************
+// MIGRATION
+// ******************************************************
+
+// readJSONFiles reads the JSON files of a profile, missing files are empty
+func readJSONFiles(dir string) (map[string]Stats, map[string]CardStats, error) {
+	j := NewJSONStore(dir)
+	st, err := readStatisticsFile(j.savFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		st, err = map[string]Stats{}, nil
+	}
+	if err != nil {
+		return nil, nil, NewError("file.readFailed", "File", j.savFile, "Err", err)
+	}
+	cs, err := readCardStatisticsFile(j.cardStatsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		cs, err = map[string]CardStats{}, nil
+	}
+	if err != nil {
+		return nil, nil, NewError("file.readFailed", "File", j.cardStatsFile, "Err", err)
+	}
+	return st, cs, nil
+}
+
+// renameMigratedJSONFiles keeps JSON files which were imported into another store
+// as *.migriert, so that they are not imported twice
+func renameMigratedJSONFiles(dir string) error {
+	j := NewJSONStore(dir)
+	for _, path := range []string{j.savFile, j.cardStatsFile} {
+		if FileExists(path) {
+			if err := os.Rename(path, path+".migriert"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJSONStoreTempFilesAndBackup(t *testing.T) {
+	dir := t.TempDir()
+	j := NewJSONStore(dir)
+	if _, err := j.AddStatistics(map[string]Stats{"a": {Count: 1, Successes: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	fresh := j.savFile + ".tmp1"
+	stale := j.savFile + ".tmp2"
+	for _, path := range []string{fresh, stale} {
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(stale, old, old)
+
+	if _, err := j.LoadStatistics(); err != nil {
+		t.Fatal(err)
+	}
+	if FileExists(j.backupFile) || !FileExists(stale) {
+		t.Error("loading changed the directory")
+	}
+
+	if _, err := j.AddStatistics(map[string]Stats{"a": {Count: 1, Successes: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if !FileExists(fresh) {
+		t.Error("a temp file another instance may be writing was removed")
+	}
+	if FileExists(stale) {
+		t.Error("the stale temp file was kept")
+	}
+	backup, err := readStatisticsFile(j.backupFile)
+	if err != nil || backup["a"].Count != 1 {
+		t.Errorf("backup = %v, %v, want the statistics before the save", backup, err)
+	}
+}
+
+func TestJSONStoreRecovery(t *testing.T) {
+	dir := t.TempDir()
+	j := NewJSONStore(dir)
+	j.AddStatistics(map[string]Stats{"a": {Count: 1, Successes: 1}})
+	j.AddStatistics(map[string]Stats{"a": {Count: 1, Successes: 1}})
+	os.WriteFile(j.savFile, []byte("{kaputt"), 0644)
+
+	st, err := NewJSONStore(dir).LoadStatistics()
+	if err == nil || st["a"].Count != 1 {
+		t.Errorf("got %v, %v, want the backup and an error", st, err)
+	}
+	damaged, _ := filepath.Glob(j.savFile + ".defekt-*")
+	if len(damaged) != 1 {
+		t.Errorf("the damaged file wasn't kept: %v", damaged)
+	}
+}
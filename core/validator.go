@@ -0,0 +1,200 @@
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+type LintKind string
+
+const (
+	LintDuplicateSource LintKind = "duplicate_source"
+	LintDuplicateCard   LintKind = "duplicate_card"
+	LintMissingWord     LintKind = "missing_word"
+	LintWhitespace      LintKind = "whitespace"
+	LintShortRow        LintKind = "short_row"
+	LintSourceIsTarget  LintKind = "source_equals_target"
+	LintMissingFile     LintKind = "missing_file"
+	LintUnreadableFile  LintKind = "unreadable_file"
+	LintUnlistedDir     LintKind = "unlisted_dir"
+)
+
+type LintIssue struct {
+	Kind     LintKind `json:"kind"`
+	LangPair string   `json:"langPair,omitempty"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Message  string   `json:"message"`
+}
+
+type LintReport struct {
+	Created time.Time   `json:"created"`
+	Issues  []LintIssue `json:"issues"`
+}
+
+// lintCard remembers where a card was seen first, to report duplicates
+type lintCard struct {
+	file   string
+	line   int
+	target string
+}
+
+func (r *LintReport) add(kind LintKind, lp string, file string, line int, msg string) {
+	r.Issues = append(r.Issues, LintIssue{Kind: kind, LangPair: lp, File: file, Line: line, Message: msg})
+}
+
+func (r LintReport) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// ******************************************************
+// VALIDATION
+// ******************************************************
+
+func ValidateDecks(conf Config) LintReport {
+	report := LintReport{Created: time.Now(), Issues: []LintIssue{}}
+
+	for _, lp := range conf.LangPairs {
+		lpstr := lp.ToString()
+		inputdir := conf.InputDirPrefix + lpstr
+		seenSources := map[string]lintCard{}
+		seenCards := map[string]lintCard{}
+
+		for _, file := range conf.GetInputFiles(lpstr) {
+			path := filepath.Join(inputdir, file.FileName)
+			if !FileExists(path) {
+				report.add(LintMissingFile, lpstr, path, 0,
+					T("lint.missingFile", "File", path, "FileList", conf.FileListConfigFile))
+				continue
+			}
+			rows, err := readCsvRows(path)
+			if err != nil {
+				report.add(LintUnreadableFile, lpstr, path, 0, T("file.readFailed", "File", path, "Err", err))
+				continue
+			}
+			lintRows(&report, lpstr, path, file, rows, seenSources, seenCards)
+		}
+	}
+
+	lintUnlistedDirs(&report, conf)
+	return report
+}
+
+func readCsvRows(path string) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	csvReader := csv.NewReader(f)
+	csvReader.Comma = ';'
+	csvReader.FieldsPerRecord = -1
+	return csvReader.ReadAll()
+}
+
+func lintRows(report *LintReport, lpstr string, path string, file InputFile, rows [][]string,
+	seenSources map[string]lintCard, seenCards map[string]lintCard) {
+
+	for i, ds := range rows {
+		lineNo := i + 1
+		if i == 0 && file.skipHeaderLine {
+			continue
+		}
+		if len(ds) <= file.sourceWordCol || len(ds) <= file.targetWordCol {
+			report.add(LintShortRow, lpstr, path, lineNo,
+				T("lint.shortRow", "Line", lineNo, "Columns", len(ds)))
+			continue
+		}
+
+		for col, cell := range ds {
+			if cell != strings.TrimSpace(cell) {
+				report.add(LintWhitespace, lpstr, path, lineNo,
+					T("lint.whitespace", "Line", lineNo, "Column", col+1, "Text", cell))
+			}
+		}
+
+		// only word and translation are needed, the comments are optional
+		// and the groups are set in the file list
+		source := strings.TrimSpace(ds[file.sourceWordCol])
+		target := strings.TrimSpace(ds[file.targetWordCol])
+		if source == "" || target == "" {
+			report.add(LintMissingWord, lpstr, path, lineNo,
+				T("lint.missingWord", "Line", lineNo))
+			continue
+		}
+		if strings.EqualFold(source, target) {
+			report.add(LintSourceIsTarget, lpstr, path, lineNo,
+				T("lint.sourceIsTarget", "Line", lineNo, "Text", source))
+		}
+
+		sourceKey := strings.ToLower(source)
+		cardKey := sourceKey + "\x00" + strings.ToLower(target)
+
+		if first, ok := seenCards[cardKey]; ok {
+			if first.file != path {
+				report.add(LintDuplicateCard, lpstr, path, lineNo,
+					T("lint.duplicateCardInFile", "Line", lineNo, "Source", source, "Target", target, "File", first.file, "FirstLine", first.line))
+			} else {
+				report.add(LintDuplicateCard, lpstr, path, lineNo,
+					T("lint.duplicateCard", "Line", lineNo, "Source", source, "Target", target, "FirstLine", first.line))
+			}
+			continue
+		}
+		seenCards[cardKey] = lintCard{file: path, line: lineNo, target: target}
+
+		if first, ok := seenSources[sourceKey]; ok {
+			report.add(LintDuplicateSource, lpstr, path, lineNo,
+				T("lint.duplicateSource", "Line", lineNo, "Source", source, "Target", target, "File", first.file, "FirstLine", first.line, "FirstTarget", first.target))
+		} else {
+			seenSources[sourceKey] = lintCard{file: path, line: lineNo, target: target}
+		}
+	}
+}
+
+// lintUnlistedDirs reports input directories which don't belong to any language pair in the file list
+func lintUnlistedDirs(report *LintReport, conf Config) {
+	if conf.InputDirPrefix == "" {
+		return
+	}
+	matches, err := filepath.Glob(conf.InputDirPrefix + "*")
+	if err != nil {
+		return
+	}
+	listed := []string{}
+	for _, lp := range conf.LangPairs {
+		listed = append(listed, filepath.Clean(conf.InputDirPrefix+lp.ToString()))
+	}
+	slices.Sort(matches)
+	for _, dir := range matches {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if !slices.Contains(listed, filepath.Clean(dir)) {
+			report.add(LintUnlistedDir, "", dir, 0,
+				T("lint.unlistedDir", "Dir", dir, "FileList", conf.FileListConfigFile))
+		}
+	}
+}
+
+// SaveLintReport writes the report to savDir and returns its path
+func SaveLintReport(savDir string, report LintReport) (string, error) {
+	if savDir == "" {
+		return "", NewError("config.noSavDir")
+	}
+	data, err := report.ToJSON()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(savDir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(savDir, "pruefbericht.json")
+	return path, os.WriteFile(path, data, 0644)
+}
@@ -1,14 +1,43 @@
 package main
 
+import (
+	"cmp"
+	"fmt"
+	"os"
+
+	"fancyCards/core"
+)
+
 func main() {
+	// until the INI is read, e.g. for the help of the flags
+	core.SetLanguage(cmp.Or(os.Getenv("FANCYCARDS_LANG"), core.SystemLanguage()))
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
 
-	if !FileExists("fancyCards.ini") {
-		CreateDefaultIni("fancyCards.ini")
+	opts, err := ParseOptions(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
-	conf, err := loadConfigsIni("fancyCards.ini")
 
-	application := InitUI(conf)
-	application.HandleErrorList(err)
+	inipath, iniErr := LocateConfigFile(opts)
+	conf, errorList := core.LoadConfig(inipath)
+	if iniErr != nil {
+		errorList = append(errorList, core.NewError("config.createFailed", "File", inipath, "Err", iniErr))
+	}
+	applyOptions(&conf, opts)
+
+	application := InitUI(conf, opts)
+	application.HandleErrorList(errorList)
 	application.window.ShowAndRun()
-	application.SaveStatistics()
+	if err := application.CloseProfile(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"image/color"
+	"time"
+
+	"fancyCards/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+var heatmapColors = []color.Color{
+	color.NRGBA{R: 0xeb, G: 0xed, B: 0xf0, A: 0xff}, // no practice
+	color.NRGBA{R: 0x9b, G: 0xe9, B: 0xa8, A: 0xff},
+	color.NRGBA{R: 0x40, G: 0xc4, B: 0x63, A: 0xff},
+	color.NRGBA{R: 0x30, G: 0xa1, B: 0x4e, A: 0xff},
+	color.NRGBA{R: 0x21, G: 0x6e, B: 0x39, A: 0xff},
+}
+
+const heatmapCellSize = 14
+
+// heatmapLevel maps the number of cards of a day to one of the heatmap colors
+func heatmapLevel(count int, maxCount int) int {
+	if count <= 0 || maxCount <= 0 {
+		return 0
+	}
+	level := 1 + (count-1)*(len(heatmapColors)-1)/maxCount
+	return min(level, len(heatmapColors)-1)
+}
+
+// NewActivityHeatmap draws one column per week (monday on top) for the given number of weeks up to the end date
+func NewActivityHeatmap(days map[string]core.Stats, end time.Time, weeks int) fyne.CanvasObject {
+	end, _ = time.Parse(core.DayLayout, end.Format(core.DayLayout))
+	offset := (int(end.Weekday()) + 6) % 7 // days since monday
+	start := end.AddDate(0, 0, -offset-7*(weeks-1))
+
+	maxCount := 0
+	for _, s := range days {
+		maxCount = max(maxCount, s.Count)
+	}
+
+	cells := []fyne.CanvasObject{}
+	for d := start; !d.After(start.AddDate(0, 0, 7*weeks-1)); d = d.AddDate(0, 0, 1) {
+		fill := color.Color(color.Transparent)
+		if !d.After(end) {
+			fill = heatmapColors[heatmapLevel(days[d.Format(core.DayLayout)].Count, maxCount)]
+		}
+		cell := canvas.NewRectangle(fill)
+		cell.SetMinSize(fyne.NewSize(heatmapCellSize, heatmapCellSize))
+		cell.CornerRadius = 2
+		cells = append(cells, cell)
+	}
+
+	legend := container.NewHBox(widget.NewLabel(core.T("heatmap.less")))
+	for _, c := range heatmapColors {
+		box := canvas.NewRectangle(c)
+		box.SetMinSize(fyne.NewSize(heatmapCellSize, heatmapCellSize))
+		legend.Add(container.NewCenter(box))
+	}
+	legend.Add(widget.NewLabel(core.T("heatmap.more")))
+
+	grid := container.NewGridWithRows(7, cells...)
+	return container.NewVBox(container.NewHBox(grid), legend)
+}
@@ -1,14 +0,0 @@
-package main
-
-import "os"
-
-func FileExists(path string) bool {
-	_, err := os.Stat(path)
-	if err == nil {
-		return true
-	}
-	if os.IsNotExist(err) {
-		return false
-	}
-	return false // some other error (permission, IO)
-}
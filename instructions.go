@@ -3,26 +3,22 @@ package main
 import (
 	"fmt"
 
+	"fancyCards/core"
+
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 )
 
 func (a *WordCardsApp) OpenInstructions() {
 
-	header := NewViewHeader("Anleitung")
-
-	instructionsText := "DATEILISTE:"
-	instructionsText += fmt.Sprintf("\nIn der Datei %s alle Dateien auflisten, aus denen Karteikarten gelesen werden sollen.", a.conf.fileListConfigFile)
-	instructionsText += "\nZEILE 1: ÜBERSCHRIFT (wird übersprungen)"
-	instructionsText += "\nSPALTE 1: Kürzel der Ausgangssprache (z. B. de)\nSPALTE 2: Kürzel der Lernsprache (z. B. fr)"
-	instructionsText += "\nSPALTE 3: Dateiname\nPro Sprache können beliebig viele Karteikarten-Dateien hier eingetragen werden."
+	header := NewViewHeader(core.T("menu.instructions"))
 
-	instructionsText += "\n\nINPUT-DATEIEN: (die Wörter für die Karteikarten)"
-	instructionsText += "\nFür jedes Sprachpaar sollen die Input-Dateien in einem eigenen Ordner liegen."
-	instructionsText += fmt.Sprintf("\nBeispiel: Deutsch-Französisch => Ordner %sde_fr", a.conf.inputDirPrefix)
-	instructionsText += "\n\nDiese Dateien müssen CSV-Tabellen sein, mit dem folgenden Aufbau:"
-	instructionsText += "\nZEILE 1: ÜBERSCHRIFT (wird übersprungen)"
-	instructionsText += "\nSPALTE 1: Wort in der Ausgangssprache\nSPALTE 2: Wort in der Lernsprache"
+	instructionsText := core.T("instructions.fileList", "File", a.conf.FileListConfigFile)
+	instructionsText += "\n\n" + core.T("instructions.inputFiles", "Prefix", a.conf.InputDirPrefix)
+	instructionsText += "\n\n" + core.T("instructions.keys")
+	for _, action := range core.KeyActions {
+		instructionsText += fmt.Sprintf("\n%s: %s", a.conf.Keys[action], core.T("keys."+action))
+	}
 	instructions := widget.NewLabel(instructionsText)
 
 	lpMenu := container.NewVBox(header, instructions, a.ReturnButton())
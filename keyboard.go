@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+
+	"fancyCards/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Practicing works without the mouse: the answer entry is focused and the keys of the
+// [KEYS] section of the INI run the actions of the practice views, see core.KeyActions.
+
+// InstallKeys passes the keys to the actions while no entry is focused, e.g. on the feedback view
+func (a *WordCardsApp) InstallKeys() {
+	a.window.Canvas().SetOnTypedKey(func(ev *fyne.KeyEvent) {
+		a.HandleKey(ev.Name, 0)
+	})
+	for _, sc := range a.conf.Keys {
+		if modifier := shortcutModifier(sc); modifier != 0 {
+			shortcut := &desktop.CustomShortcut{KeyName: fyne.KeyName(sc.Key), Modifier: modifier}
+			a.window.Canvas().AddShortcut(shortcut, func(fyne.Shortcut) {
+				a.HandleKey(shortcut.KeyName, shortcut.Modifier)
+			})
+		}
+	}
+}
+
+// HandleKey runs the action of the current view bound to the key, it tells if there was one
+func (a *WordCardsApp) HandleKey(name fyne.KeyName, modifier fyne.KeyModifier) bool {
+	if name == fyne.KeyEnter {
+		name = fyne.KeyReturn // the one of the number pad
+	}
+	for action, run := range a.keyActions {
+		sc := a.conf.Keys[action]
+		if fyne.KeyName(sc.Key) == name && shortcutModifier(sc) == modifier {
+			run()
+			return true
+		}
+	}
+	return false
+}
+
+func shortcutModifier(sc core.Shortcut) fyne.KeyModifier {
+	var modifier fyne.KeyModifier
+	if sc.Ctrl {
+		modifier |= fyne.KeyModifierControl
+	}
+	if sc.Alt {
+		modifier |= fyne.KeyModifierAlt
+	}
+	if sc.Shift {
+		modifier |= fyne.KeyModifierShift
+	}
+	return modifier
+}
+
+// KeyLabel adds the key of the action to the text of a button, e.g. "Tipp (Ctrl+H)"
+func (a *WordCardsApp) KeyLabel(text string, action string) string {
+	return fmt.Sprintf("%s (%s)", text, a.conf.Keys[action])
+}
+
+// ******************************************************
+// ANSWER ENTRY
+// ******************************************************
+
+// answerEntry runs the actions of the keys instead of typing them
+type answerEntry struct {
+	widget.Entry
+	app *WordCardsApp
+}
+
+func newAnswerEntry(a *WordCardsApp) *answerEntry {
+	e := &answerEntry{app: a}
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+func (e *answerEntry) TypedKey(ev *fyne.KeyEvent) {
+	if !e.app.HandleKey(ev.Name, 0) {
+		e.Entry.TypedKey(ev)
+	}
+}
+
+func (e *answerEntry) TypedShortcut(s fyne.Shortcut) {
+	if cs, ok := s.(*desktop.CustomShortcut); ok && e.app.HandleKey(cs.KeyName, cs.Modifier) {
+		return
+	}
+	e.Entry.TypedShortcut(s)
+}
+
+// CharacterPalette offers the special characters of the asked language as buttons,
+// a click inserts the character at the cursor of the entry
+func (a *WordCardsApp) CharacterPalette(entry *answerEntry) fyne.CanvasObject {
+	palette := container.NewGridWrap(fyne.NewSize(44, 36))
+	for _, sc := range a.session.Deck.Characters {
+		palette.Add(widget.NewButton(sc.Char, func() {
+			for _, r := range sc.Char {
+				entry.TypedRune(r)
+			}
+			a.window.Canvas().Focus(entry)
+		}))
+	}
+	return palette
+}
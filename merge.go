@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fancyCards/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+)
+
+// MergeDialog asks for the copied savDir or profile directory of the other device
+func (a *WordCardsApp) MergeDialog() {
+	dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if uri == nil {
+			return
+		}
+		result, err := a.profile.MergeFrom(uri.Path())
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		msg := core.T("merge.result", "Source", result.Source, "Events", result.NewEvents, "Days", result.MergedDays)
+		dialog.ShowInformation(core.T("merge.done"), msg, a.window)
+	}, a.window)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fancyCards/core"
+)
+
+// Options can be given as flags or environment variables, flags win.
+// Without a config file, the INI is searched in the user's config directory
+// (e.g. ~/.config/fancyCards or %AppData%\fancyCards) and then in the
+// current directory ("portable mode", e.g. on a USB stick).
+
+const iniFileName = "fancyCards.ini"
+
+type Options struct {
+	configFile string
+	savDir     string // overrides savDir of the INI
+	profile    string
+	pair       string // e.g. "de_fr", "fr_de" opens the reverse direction
+	lang       string // language of the user interface, overrides the INI
+}
+
+// newFlagSet registers the options all commands share
+func newFlagSet(name string, opts *Options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&opts.configFile, "config", os.Getenv("FANCYCARDS_CONFIG"), core.T("flag.config"))
+	fs.StringVar(&opts.savDir, "savdir", os.Getenv("FANCYCARDS_SAVDIR"), core.T("flag.savdir"))
+	fs.StringVar(&opts.profile, "profile", os.Getenv("FANCYCARDS_PROFILE"), core.T("flag.profile"))
+	fs.StringVar(&opts.lang, "lang", os.Getenv("FANCYCARDS_LANG"), core.T("flag.lang", "Languages", strings.Join(core.Languages(), ", ")))
+	return fs
+}
+
+func ParseOptions(args []string) (Options, error) {
+	opts := Options{}
+	fs := newFlagSet("fancyCards", &opts)
+	fs.StringVar(&opts.pair, "pair", os.Getenv("FANCYCARDS_PAIR"), core.T("flag.pair"))
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, core.NewError("cli.unknownArgument", "Arg", fs.Arg(0))
+	}
+	return opts, nil
+}
+
+// userConfigFile is the INI in the user's config directory, "" if there is none
+func userConfigFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "fancyCards", iniFileName)
+}
+
+// findConfigFile returns the INI to use without creating one, false if it doesn't exist
+func findConfigFile(opts Options) (string, bool) {
+	if opts.configFile != "" {
+		return opts.configFile, core.FileExists(opts.configFile)
+	}
+	userFile := userConfigFile()
+	if userFile != "" && core.FileExists(userFile) {
+		return userFile, true
+	}
+	if core.FileExists(iniFileName) {
+		return iniFileName, true
+	}
+	return iniFileName, false
+}
+
+// LocateConfigFile returns the INI to use and creates a default one if there is none yet
+func LocateConfigFile(opts Options) (string, error) {
+	if path, ok := findConfigFile(opts); ok {
+		return path, nil
+	}
+	if opts.configFile != "" {
+		return opts.configFile, core.CreateDefaultIni(opts.configFile)
+	}
+	userFile := userConfigFile()
+	if userFile != "" && core.CreateDefaultIni(userFile) == nil {
+		return userFile, nil
+	}
+	return iniFileName, core.CreateDefaultIni(iniFileName)
+}
+
+// applyOptions overrides the INI with the options given on the command line
+// and switches to the language of the user interface
+func applyOptions(conf *core.Config, opts Options) {
+	if opts.savDir != "" {
+		conf.SavDir = opts.savDir
+	}
+	if opts.lang != "" {
+		conf.UILanguage = opts.lang
+	}
+	if conf.UILanguage != "" {
+		core.SetLanguage(conf.UILanguage)
+	}
+}
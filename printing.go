@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"fancyCards/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Printing a deck on paper:
+//
+//	fancyCards print de_fr -groups Gruppe1,Gruppe2 [-reverse] [-worksheet] -o karten.pdf
+
+// ******************************************************
+// COMMAND
+// ******************************************************
+
+func RunPrint(args []string) error {
+	opts := Options{}
+	fs := newFlagSet("print", &opts)
+	groups := fs.String("groups", "", core.T("flag.groups"))
+	reverse := fs.Bool("reverse", false, core.T("flag.reverse"))
+	worksheet := fs.Bool("worksheet", false, core.T("flag.worksheet"))
+	output := fs.String("o", "", core.T("flag.pdfOutput"))
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || *output == "" {
+		return core.NewError("cli.usage", "Usage", core.T("usage.print"))
+	}
+
+	conf, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	lp, reversed, err := conf.FindLangPair(positional[0])
+	if err != nil {
+		return err
+	}
+	groupList := []string{}
+	for _, g := range strings.Split(*groups, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			groupList = append(groupList, g)
+		}
+	}
+	deck, err := core.LoadDeck(conf, nil, lp, reversed != *reverse, groupList)
+	if err != nil {
+		return err
+	}
+	layout := core.FlashcardLayout
+	if *worksheet {
+		layout = core.WorksheetLayout
+	}
+
+	var buf bytes.Buffer
+	if err := core.WritePDF(&buf, conf, deck, layout); err != nil {
+		return err
+	}
+	if err := os.WriteFile(*output, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Println(core.T("print.written", "File", *output, "Count", len(deck.Cards)))
+	return nil
+}
+
+// ******************************************************
+// VIEWS
+// ******************************************************
+
+// PrintDialog asks for the layout and the groups of the selected pair and saves the PDF
+func (a *WordCardsApp) PrintDialog() {
+	flashcardLayoutName, worksheetLayoutName := core.T("print.flashcards"), core.T("print.worksheet")
+	layout := widget.NewRadioGroup([]string{flashcardLayoutName, worksheetLayoutName}, nil)
+	layout.SetSelected(flashcardLayoutName)
+	content := container.NewVBox(layout)
+	groups := widget.NewCheckGroup(a.conf.GetGroups(a.selectedLP), nil)
+	if len(groups.Options) > 0 {
+		content.Add(widget.NewLabel(core.T("print.groups")))
+		content.Add(groups)
+	}
+
+	dialog.ShowCustomConfirm(core.T("button.print"), core.T("button.continue"), core.T("button.cancel"), content, func(ok bool) {
+		if !ok {
+			return
+		}
+		deck, err := a.LoadDeck(groups.Selected)
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		printLayout := core.FlashcardLayout
+		if layout.Selected == worksheetLayoutName {
+			printLayout = core.WorksheetLayout
+		}
+		// create the PDF first, so that no empty file is left behind on errors
+		var buf bytes.Buffer
+		if err := core.WritePDF(&buf, a.conf, deck, printLayout); err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, a.window)
+				return
+			}
+			if writer == nil {
+				return // cancelled
+			}
+			defer writer.Close()
+			if _, err := writer.Write(buf.Bytes()); err != nil {
+				dialog.ShowError(err, a.window)
+			}
+		}, a.window)
+		name := core.T("print.flashcardsFile")
+		if printLayout == core.WorksheetLayout {
+			name = core.T("print.worksheetFile")
+		}
+		saveDialog.SetFileName(fmt.Sprintf("%s-%s.pdf", name, deck.AskedPair().ToString()))
+		saveDialog.Show()
+	}, a.window)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+
+	"fancyCards/core"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ******************************************************
+// SWITCHING PROFILES
+// ******************************************************
+
+// InitializeProfiles selects the given or the only profile right away, otherwise the user picks one
+func (a *WordCardsApp) InitializeProfiles(name string) {
+	if a.conf.SavDir == "" {
+		a.HandleError(core.NewError("config.noSavDir"))
+		return
+	}
+	if err := core.MigrateToProfiles(a.conf.SavDir); err != nil {
+		a.HandleError(err)
+		return
+	}
+	profiles := core.ListProfiles(a.conf.SavDir)
+	switch {
+	case name != "" && slices.Contains(profiles, name):
+		a.SelectProfile(name)
+	case name != "":
+		a.ShowProfiles()
+		dialog.ShowError(core.NewError("profile.unknown", "Profile", name), a.window)
+	case len(profiles) == 1:
+		a.SelectProfile(profiles[0])
+	default:
+		a.ShowProfiles()
+	}
+}
+
+// SelectProfile saves and closes the data of the current profile and loads the selected one
+func (a *WordCardsApp) SelectProfile(name string) {
+	a.ShowSaveError(a.CloseProfile())
+	errorList := a.OpenProfile(name)
+
+	a.CreateMainMenu(a.conf)
+	a.ToMainMenu()
+	a.HandleErrorList(errorList)
+	a.AcquireLock()
+}
+
+// OpenProfile loads statistics, card statistics and preferences of a profile
+func (a *WordCardsApp) OpenProfile(name string) []error {
+	profile, errorList := core.OpenProfile(a.conf.SavDir, name, a.conf.Storage)
+	a.profile = profile
+	return errorList
+}
+
+// CloseProfile saves everything and releases the profile's directory
+func (a *WordCardsApp) CloseProfile() error {
+	if a.profile.Name == "" {
+		return nil
+	}
+	err := errors.Join(a.profile.Close(), a.cardIndex.Save(a.conf.SavDir))
+	a.profile = core.InMemoryProfile()
+	a.session = nil
+	return err
+}
+
+// ******************************************************
+// VIEWS
+// ******************************************************
+
+func (a *WordCardsApp) ShowProfiles() {
+	viewHeader := NewViewHeader(core.T("profiles.header"))
+	profileList := container.NewVBox()
+
+	for _, name := range core.ListProfiles(a.conf.SavDir) {
+		label := name
+		if name == a.profile.Name {
+			label += " " + core.T("profiles.active")
+		}
+		selectButton := widget.NewButton(label, func() {
+			a.SelectProfile(name)
+		})
+		renameButton := widget.NewButton(core.T("button.rename"), func() {
+			a.RenameProfileDialog(name)
+		})
+		deleteButton := widget.NewButton(core.T("button.delete"), func() {
+			a.DeleteProfileDialog(name)
+		})
+		if name == a.profile.Name {
+			deleteButton.Disable()
+		}
+		profileList.Add(container.NewBorder(nil, nil, nil, container.NewHBox(renameButton, deleteButton), selectButton))
+	}
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder(core.T("profiles.newName"))
+	createButton := widget.NewButton(core.T("profiles.create"), func() {
+		if err := core.CreateProfile(a.conf.SavDir, nameEntry.Text); err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		a.ShowProfiles()
+	})
+
+	content := container.NewVBox(viewHeader, profileList, nameEntry, createButton)
+	if a.profile.Name != "" {
+		content.Add(widget.NewButton(core.T("profiles.preferences"), func() {
+			a.ShowPreferences()
+		}))
+		content.Add(widget.NewButton(core.T("profiles.merge"), func() {
+			a.MergeDialog()
+		}))
+		content.Add(widget.NewButton(core.T("backup.create"), func() {
+			a.BackupDialog()
+		}))
+	}
+	content.Add(widget.NewButton(core.T("backup.restore"), func() {
+		a.RestoreDialog()
+	}))
+	if a.profile.Name != "" {
+		content.Add(a.ReturnButton())
+	}
+	a.window.SetContent(content)
+}
+
+func (a *WordCardsApp) RenameProfileDialog(name string) {
+	entry := widget.NewEntry()
+	entry.SetText(name)
+	items := []*widget.FormItem{widget.NewFormItem(core.T("profiles.renameNew"), entry)}
+	dialog.ShowForm(core.T("profiles.rename"), core.T("button.rename"), core.T("button.cancel"), items, func(ok bool) {
+		if !ok || entry.Text == name {
+			return
+		}
+		active := name == a.profile.Name
+		if active {
+			a.ShowSaveError(a.CloseProfile())
+		}
+		err := core.RenameProfile(a.conf.SavDir, name, entry.Text)
+		if active {
+			if err == nil {
+				name = strings.TrimSpace(entry.Text)
+			}
+			a.SelectProfile(name)
+		}
+		if err != nil {
+			dialog.ShowError(err, a.window)
+		}
+		a.ShowProfiles()
+	}, a.window)
+}
+
+func (a *WordCardsApp) DeleteProfileDialog(name string) {
+	msg := core.T("profiles.deleteConfirm", "Profile", name)
+	dialog.ShowConfirm(core.T("profiles.delete"), msg, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := core.DeleteProfile(a.conf.SavDir, name); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+		a.ShowProfiles()
+	}, a.window)
+}
+
+func (a *WordCardsApp) ShowPreferences() {
+	viewHeader := NewViewHeader(core.T("prefs.header", "Profile", a.profile.Name))
+
+	modes := map[string]core.PracticeMode{
+		core.T("prefs.random"):  core.PracticeRandom,
+		core.T("prefs.hardest"): core.PracticeHardest,
+	}
+	strictness := map[string]core.Strictness{
+		core.T("prefs.lenient"): core.StrictnessLenient,
+		core.T("prefs.normal"):  core.StrictnessNormal,
+		core.T("prefs.strict"):  core.StrictnessStrict,
+	}
+
+	save := func() {
+		if err := core.SavePreferences(a.profile.Dir, a.profile.Prefs); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	}
+
+	modeSelect := widget.NewSelect(sortedKeys(modes), func(option string) {
+		a.profile.Prefs.PracticeMode = modes[option]
+		save()
+	})
+	strictSelect := widget.NewSelect(sortedKeys(strictness), func(option string) {
+		a.profile.Prefs.Strictness = strictness[option]
+		save()
+	})
+	for option, mode := range modes {
+		if mode == a.profile.Prefs.PracticeMode {
+			modeSelect.Selected = option
+		}
+	}
+	for option, s := range strictness {
+		if s == a.profile.Prefs.Strictness {
+			strictSelect.Selected = option
+		}
+	}
+
+	// the session lengths in the order they are offered
+	lengths := []struct {
+		option         string
+		cards, minutes int
+	}{
+		{core.T("prefs.unlimited"), 0, 0},
+		{core.T("prefs.cards", "Count", 10), 10, 0},
+		{core.T("prefs.cards", "Count", 20), 20, 0},
+		{core.T("prefs.cards", "Count", 50), 50, 0},
+		{core.T("prefs.minutes", "Count", 5), 0, 5},
+		{core.T("prefs.minutes", "Count", 10), 0, 10},
+		{core.T("prefs.minutes", "Count", 15), 0, 15},
+	}
+	lengthOptions := []string{}
+	for _, l := range lengths {
+		lengthOptions = append(lengthOptions, l.option)
+	}
+	lengthSelect := widget.NewSelect(lengthOptions, func(option string) {
+		i := slices.Index(lengthOptions, option)
+		a.profile.Prefs.SessionCards, a.profile.Prefs.SessionMinutes = lengths[i].cards, lengths[i].minutes
+		save()
+	})
+	for _, l := range lengths {
+		if l.cards == a.profile.Prefs.SessionCards && l.minutes == a.profile.Prefs.SessionMinutes {
+			lengthSelect.Selected = l.option
+		}
+	}
+
+	pinEntry := widget.NewPasswordEntry()
+	pinEntry.SetPlaceHolder(core.T("prefs.noPIN"))
+	pinButton := widget.NewButton(core.T("prefs.savePIN"), func() {
+		a.profile.Prefs.PIN = ""
+		if pinEntry.Text != "" {
+			a.profile.Prefs.PIN = core.HashPIN(pinEntry.Text)
+		}
+		pinEntry.SetText("")
+		save()
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem(core.T("prefs.mode"), modeSelect),
+		widget.NewFormItem(core.T("report.strictness"), strictSelect),
+		widget.NewFormItem(core.T("prefs.sessionLength"), lengthSelect),
+		widget.NewFormItem(core.T("prefs.pin"), container.NewBorder(nil, nil, nil, pinButton, pinEntry)),
+	)
+	backButton := widget.NewButton(core.T("button.back"), func() {
+		a.ShowProfiles()
+	})
+	a.window.SetContent(container.NewVBox(viewHeader, form, backButton, a.ReturnButton()))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"fancyCards/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+)
+
+// SaveReportDialog saves the answers of the current session as an HTML page
+func (a *WordCardsApp) SaveReportDialog() {
+	var buf bytes.Buffer
+	if err := core.WriteReport(&buf, a.conf, a.session); err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if writer == nil {
+			return // cancelled
+		}
+		defer writer.Close()
+		if _, err := writer.Write(buf.Bytes()); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	}, a.window)
+	saveDialog.SetFileName(fmt.Sprintf("%s-%s-%s.html", core.T("report.fileName"), a.session.Deck.AskedPair().ToString(), a.session.Started.Format("2006-01-02-1504")))
+	saveDialog.Show()
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"fancyCards/core"
+)
+
+// Commands for scripts and cron jobs, they only print and never open a window:
+//
+//	fancyCards validate                    exit code 1 if the INI is missing or it, the file list or a deck has errors
+//	fancyCards stats [de_fr] [-json|-csv]  monthly statistics of the profile
+
+// ******************************************************
+// VALIDATE
+// ******************************************************
+
+func RunValidate(args []string) error {
+	opts := Options{}
+	fs := newFlagSet("validate", &opts)
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return core.NewError("cli.usage", "Usage", core.T("usage.validate"))
+	}
+
+	// validate reports all errors of the INI instead of stopping at the first
+	inipath, ok := findConfigFile(opts)
+	if !ok {
+		return core.NewError("config.notFound", "File", inipath)
+	}
+	conf, errorList := core.LoadConfig(inipath)
+	applyOptions(&conf, opts)
+
+	cards := 0
+	for _, lp := range conf.LangPairs {
+		count, deckErrors := core.CheckDeckFiles(conf, lp)
+		for _, err := range deckErrors {
+			errorList = append(errorList, fmt.Errorf("%s: %v", lp.ToString(), err))
+		}
+		cards += count
+	}
+
+	for _, err := range errorList {
+		fmt.Println(err)
+	}
+	if len(errorList) > 0 {
+		return core.NewError("validate.failed", "File", inipath, "Count", len(errorList))
+	}
+	fmt.Println(core.T("validate.ok", "File", inipath, "Pairs", len(conf.LangPairs), "Cards", cards))
+	return nil
+}
+
+// ******************************************************
+// STATS
+// ******************************************************
+
+type monthlyStatsRow struct {
+	Pair  string `json:"pair"`
+	Month string `json:"month"` // yyyy-mm
+	core.Stats
+}
+
+func RunStats(args []string) error {
+	opts := Options{}
+	fs := newFlagSet("stats", &opts)
+	asJSON := fs.Bool("json", false, core.T("flag.json"))
+	asCSV := fs.Bool("csv", false, core.T("flag.csv"))
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 || (*asJSON && *asCSV) {
+		return core.NewError("cli.usage", "Usage", core.T("usage.stats"))
+	}
+	conf, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	if conf.SavDir == "" {
+		return core.NewError("config.noSavDir")
+	}
+
+	pairs := []core.LangPair{}
+	if len(positional) == 1 {
+		lp, reversed, err := conf.FindLangPair(positional[0])
+		if err != nil {
+			return err
+		}
+		if reversed {
+			lp = lp.Flip()
+		}
+		pairs = append(pairs, lp)
+	} else {
+		for _, lp := range conf.LangPairs {
+			pairs = append(pairs, lp)
+			if !conf.LangPairExists(lp.Flip()) {
+				pairs = append(pairs, lp.Flip())
+			}
+		}
+	}
+
+	name, err := core.StartupProfile(conf.SavDir, opts.profile)
+	if err != nil {
+		return err
+	}
+	profile, errorList := core.OpenProfile(conf.SavDir, name, conf.Storage)
+	for _, err := range errorList {
+		fmt.Fprintln(os.Stderr, core.T("cli.note"), err)
+	}
+	defer profile.Close()
+
+	rows := monthlyStatsRows(profile, pairs)
+	switch {
+	case *asJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case *asCSV:
+		return writeStatsCSV(rows)
+	}
+	writeStatsTable(rows)
+	return nil
+}
+
+// monthlyStatsRows lists the months with answers, per pair in chronological order
+func monthlyStatsRows(profile *core.Profile, pairs []core.LangPair) []monthlyStatsRow {
+	rows := []monthlyStatsRow{}
+	for _, lp := range pairs {
+		pairRows := []monthlyStatsRow{}
+		for key, st := range profile.MonthlyStats(lp) {
+			month, err := time.Parse("1/2006", key)
+			if err != nil {
+				continue
+			}
+			pairRows = append(pairRows, monthlyStatsRow{Pair: lp.ToString(), Month: month.Format("2006-01"), Stats: st})
+		}
+		slices.SortFunc(pairRows, func(x, y monthlyStatsRow) int { return strings.Compare(x.Month, y.Month) })
+		rows = append(rows, pairRows...)
+	}
+	return rows
+}
+
+func writeStatsCSV(rows []monthlyStatsRow) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"pair", "month", "count", "successes", "similar", "mistakes", "skipped", "accents", "typos"})
+	for _, r := range rows {
+		w.Write([]string{r.Pair, r.Month, strconv.Itoa(r.Count), strconv.Itoa(r.Successes), strconv.Itoa(r.Similar),
+			strconv.Itoa(r.Mistakes), strconv.Itoa(r.Skipped), strconv.Itoa(r.Accents), strconv.Itoa(r.Typos)})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeStatsTable(rows []monthlyStatsRow) {
+	if len(rows) == 0 {
+		fmt.Println(core.T("stats.none"))
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join([]string{core.T("stats.pair"), core.T("stats.month"), core.T("stats.answers"),
+		core.T("stats.correct"), core.T("stats.similar"), core.T("stats.wrong"), core.T("stats.skipped"), core.T("stats.rate")}, "\t"))
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%.0f %%\n",
+			r.Pair, r.Month, r.Count, r.Successes, r.Similar, r.Mistakes, r.Skipped, r.SuccessRate())
+	}
+	w.Flush()
+}
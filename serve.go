@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"fancyCards/core"
+	"fancyCards/web"
+)
+
+// Practicing in the browser of a phone in the home network:
+//
+//	fancyCards serve [-addr :8080] [-edit]
+//
+// Ctrl-C stops the server and saves all profiles.
+
+func RunServe(args []string) error {
+	opts := Options{}
+	fs := newFlagSet("serve", &opts)
+	addr := fs.String("addr", ":8080", core.T("flag.addr"))
+	edit := fs.Bool("edit", false, core.T("flag.edit"))
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return core.NewError("cli.usage", "Usage", core.T("usage.serve"))
+	}
+	conf, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	server, err := web.NewServer(conf)
+	if err != nil {
+		return err
+	}
+	server.AllowEditing = *edit
+
+	httpServer := &http.Server{Addr: *addr, Handler: server.Handler()}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		httpServer.Shutdown(context.Background())
+	}()
+
+	for _, url := range lanURLs(*addr) {
+		fmt.Println(core.T("serve.running", "URL", url))
+	}
+	err = httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	return errors.Join(err, server.Close())
+}
+
+// lanURLs lists the addresses under which other devices can reach the server
+func lanURLs(addr string) []string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return []string{}
+	}
+	if host != "" {
+		return []string{"http://" + net.JoinHostPort(host, port)}
+	}
+	urls := []string{}
+	ifaceAddrs, _ := net.InterfaceAddrs()
+	for _, a := range ifaceAddrs {
+		if ipnet, ok := a.(*net.IPNet); ok && ipnet.IP.To4() != nil && !ipnet.IP.IsLoopback() {
+			urls = append(urls, "http://"+net.JoinHostPort(ipnet.IP.String(), port))
+		}
+	}
+	return append(urls, "http://"+net.JoinHostPort("localhost", port))
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fancyCards/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// A session ends after the cards or minutes set in the preferences of the profile,
+// or with "Sitzung beenden". The summary shows the result and offers to retry the failed cards.
+
+// ******************************************************
+// PROGRESS
+// ******************************************************
+
+// SessionProgress shows the running score and, for a session with a limit, a progress bar.
+// With a time limit it is updated every second until the next view, and the summary is
+// shown when the time is up, even if the card wasn't answered.
+func (a *WordCardsApp) SessionProgress() fyne.CanvasObject {
+	s := a.session
+	bar := widget.NewProgressBar()
+	bar.TextFormatter = s.LimitText
+	score := widget.NewLabel("")
+	score.Alignment = fyne.TextAlignCenter
+	update := func() {
+		bar.SetValue(s.Progress())
+		score.SetText(core.T("session.score", "Correct", s.Stats.Successes, "Count", s.Stats.Count,
+			"Time", core.FormatDuration(s.Elapsed())))
+	}
+	update()
+
+	a.StopTimer()
+	if s.Limit.Duration > 0 {
+		stop := make(chan struct{})
+		a.stopTimer = stop
+		go func() {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+					if s.Elapsed() >= s.Limit.Duration {
+						fyne.Do(func() {
+							if a.session == s {
+								a.ShowSessionSummary()
+							}
+						})
+						return
+					}
+					fyne.Do(update)
+				}
+			}
+		}()
+	}
+	if s.Limit == (core.SessionLimit{}) {
+		return score
+	}
+	return container.NewVBox(bar, score)
+}
+
+// StopTimer ends the updates of the session progress
+func (a *WordCardsApp) StopTimer() {
+	if a.stopTimer != nil {
+		close(a.stopTimer)
+		a.stopTimer = nil
+	}
+}
+
+// ******************************************************
+// SUMMARY
+// ******************************************************
+
+func (a *WordCardsApp) ShowSessionSummary() {
+	a.StopTimer()
+	s := a.session
+	a.keyActions = map[string]func(){core.KeyMenu: a.ToMainMenu}
+
+	header := NewViewHeader(core.T("session.summary"))
+	result := widget.NewLabel(core.T("session.result", "Rate", fmt.Sprintf("%.0f", s.Stats.SuccessRate()),
+		"Correct", s.Stats.Successes, "Count", s.Stats.Count))
+	duration := widget.NewLabel(core.T("session.time", "Time", core.FormatDuration(s.Elapsed())))
+
+	failedList := container.NewVBox()
+	failed := s.FailedCards()
+	if len(failed) == 0 && s.Stats.Count > 0 {
+		failedList.Add(widget.NewLabel(core.T("session.noneFailed")))
+	} else if len(failed) > 0 {
+		failedList.Add(NewViewHeader(core.T("session.failed", "Count", len(failed))))
+	}
+	for _, wc := range failed {
+		failedList.Add(widget.NewLabel(fmt.Sprintf("%s => %s", wc.Source, wc.Target)))
+	}
+
+	retryBtn := widget.NewButton(a.KeyLabel(core.T("session.retry"), core.KeyCheck), nil)
+	retryBtn.OnTapped = func() {
+		a.session = s.RetryFailed()
+		a.LoadRandomCard()
+	}
+	if len(failed) > 0 {
+		a.keyActions[core.KeyCheck] = retryBtn.OnTapped
+	} else {
+		retryBtn.Disable()
+	}
+	reportBtn := widget.NewButton(core.T("button.saveReport"), func() {
+		a.SaveReportDialog()
+	})
+	if len(s.Answers) == 0 {
+		reportBtn.Disable()
+	}
+
+	top := container.NewVBox(header, result, duration)
+	bottom := container.NewVBox(retryBtn, reportBtn, a.ReturnButton())
+	a.window.SetContent(container.NewBorder(top, bottom, nil, nil, container.NewVScroll(failedList)))
+}
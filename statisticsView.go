@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fancyCards/core"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// the directions and ranges are message IDs, the selects show their texts
+const (
+	directionForward = "stats.forward"
+	directionReverse = "stats.reverse"
+	directionBoth    = "stats.both"
+)
+
+type statsRange struct {
+	label   string
+	days    int // 0 = everything
+	monthly bool
+}
+
+var statsRanges = []statsRange{
+	{label: "stats.last30Days", days: 30},
+	{label: "stats.last6Months", days: 182, monthly: true},
+	{label: "stats.last12Months", days: 365, monthly: true},
+	{label: "stats.allTime", monthly: true},
+}
+
+type statsFilter struct {
+	lp        core.LangPair
+	direction string
+	dateRange statsRange
+}
+
+func (f statsFilter) langPairs() []core.LangPair {
+	switch f.direction {
+	case directionReverse:
+		return []core.LangPair{f.lp.Flip()}
+	case directionBoth:
+		return []core.LangPair{f.lp, f.lp.Flip()}
+	}
+	return []core.LangPair{f.lp}
+}
+
+func (a *WordCardsApp) filteredDailyStats(f statsFilter) map[string]core.Stats {
+	mp := map[string]core.Stats{}
+	for _, lp := range f.langPairs() {
+		for day, value := range a.profile.DailyStats(lp) {
+			mp[day] = mp[day].Add(value)
+		}
+	}
+	return mp
+}
+
+// ******************************************************
+// VIEW
+// ******************************************************
+
+func (a *WordCardsApp) ShowStatSummary() {
+	filter := statsFilter{lp: a.selectedLP, direction: directionForward, dateRange: statsRanges[0]}
+	if a.reverse {
+		filter.direction = directionReverse
+	}
+
+	pairOptions := []string{}
+	pairsByOption := map[string]core.LangPair{}
+	for _, lp := range a.conf.LangPairs {
+		option := a.conf.GetLangPairAsString(lp)
+		pairOptions = append(pairOptions, option)
+		pairsByOption[option] = lp
+	}
+	rangeOptions := []string{}
+	for _, r := range statsRanges {
+		rangeOptions = append(rangeOptions, core.T(r.label))
+	}
+
+	content := container.NewVBox()
+	refresh := func() {
+		content.Objects = []fyne.CanvasObject{a.statisticsContent(filter)}
+		content.Refresh()
+	}
+
+	pairSelect := widget.NewSelect(pairOptions, func(option string) {
+		filter.lp = pairsByOption[option]
+		refresh()
+	})
+	pairSelect.SetSelected(a.conf.GetLangPairAsString(filter.lp))
+
+	directionOptions := []string{}
+	directionsByOption := map[string]string{}
+	for _, d := range []string{directionForward, directionReverse, directionBoth} {
+		directionOptions = append(directionOptions, core.T(d))
+		directionsByOption[core.T(d)] = d
+	}
+	directionSelect := widget.NewSelect(directionOptions, func(option string) {
+		filter.direction = directionsByOption[option]
+		refresh()
+	})
+	directionSelect.SetSelected(core.T(filter.direction))
+
+	rangeSelect := widget.NewSelect(rangeOptions, func(option string) {
+		for _, r := range statsRanges {
+			if core.T(r.label) == option {
+				filter.dateRange = r
+			}
+		}
+		refresh()
+	})
+	rangeSelect.SetSelected(core.T(filter.dateRange.label))
+
+	filters := container.NewGridWithColumns(3, pairSelect, directionSelect, rangeSelect)
+	top := container.NewVBox(NewViewHeader(core.T("stats.header")), filters)
+	refresh()
+
+	rebuildButton := widget.NewButton(core.T("stats.rebuild"), func() {
+		dialog.ShowConfirm(core.T("stats.rebuildTitle"), core.T("stats.rebuildConfirm"),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if err := a.profile.RebuildStatisticsFromLog(); err != nil {
+					dialog.ShowError(err, a.window)
+				}
+				refresh()
+			}, a.window)
+	})
+	bottom := container.NewVBox(rebuildButton, a.ReturnButton())
+
+	a.window.SetContent(container.NewBorder(top, bottom, nil, nil, container.NewVScroll(content)))
+}
+
+func (a *WordCardsApp) statisticsContent(f statsFilter) fyne.CanvasObject {
+	dailyStats := a.filteredDailyStats(f)
+	today := time.Now()
+	current, longest := core.CalculateStreaks(dailyStats, today)
+	currentAll, longestAll := core.CalculateStreaks(a.profile.AllDailyStats(), today)
+
+	from := time.Time{}
+	if f.dateRange.days > 0 {
+		from = today.AddDate(0, 0, -f.dateRange.days+1)
+	}
+	series := core.StatsSeries(dailyStats, from, today, f.dateRange.monthly)
+
+	page := container.NewVBox(
+		widget.NewLabel(core.T("stats.streak", "Current", current, "Longest", longest)),
+		widget.NewLabel(core.T("stats.streakAll", "Current", currentAll, "Longest", longestAll)),
+		NewActivityHeatmap(dailyStats, today, 20),
+	)
+
+	total := core.Stats{}
+	for _, p := range series {
+		total = total.Add(p.Stats)
+	}
+	if total.Count == 0 {
+		page.Add(widget.NewLabel(core.T("stats.noneForCards")))
+		return page
+	}
+
+	page.Add(widget.NewLabel(core.T("stats.total", "Count", total.Count, "Correct", total.Successes, "Similar", total.Similar,
+		"Wrong", total.Mistakes, "Skipped", total.Skipped, "Rate", fmt.Sprintf("%.0f", total.SuccessRate()))))
+	if total.Accents > 0 || total.Typos > 0 {
+		page.Add(widget.NewLabel(core.T("stats.accentsTypos", "Accents", total.Accents, "Typos", total.Typos)))
+	}
+
+	labels := []string{}
+	volume := []float64{}
+	rate := []float64{}
+	for _, p := range series {
+		labels = append(labels, p.Label)
+		volume = append(volume, float64(p.Stats.Count))
+		rate = append(rate, p.Stats.SuccessRate())
+	}
+	volumeChart := NewChart(core.T("stats.cards"), BarChart, labels, volume, "")
+	rateChart := NewChart(core.T("stats.successRate"), LineChart, labels, rate, "%")
+
+	page.Add(rateChart.Render())
+	page.Add(a.ExportChartButton(rateChart, "erfolgsquote.png"))
+	page.Add(volumeChart.Render())
+	page.Add(a.ExportChartButton(volumeChart, "karteikarten.png"))
+	page.Add(statsTable(series))
+	return page
+}
+
+func statsTable(series []core.StatPeriod) fyne.CanvasObject {
+	table := container.NewGridWithColumns(7)
+	for _, title := range []string{core.T("stats.period"), core.T("stats.cards"), core.T("stats.correct"), core.T("stats.similar"),
+		core.T("stats.wrong"), core.T("stats.skipped"), core.T("stats.successRate")} {
+		label := widget.NewLabel(title)
+		label.TextStyle = fyne.TextStyle{Bold: true}
+		table.Add(label)
+	}
+	for _, p := range series {
+		if p.Stats.Count == 0 {
+			continue
+		}
+		table.Add(widget.NewLabel(p.Label))
+		table.Add(widget.NewLabel(fmt.Sprint(p.Stats.Count)))
+		table.Add(widget.NewLabel(fmt.Sprint(p.Stats.Successes)))
+		table.Add(widget.NewLabel(fmt.Sprint(p.Stats.Similar)))
+		table.Add(widget.NewLabel(fmt.Sprint(p.Stats.Mistakes)))
+		table.Add(widget.NewLabel(fmt.Sprint(p.Stats.Skipped)))
+		table.Add(widget.NewLabel(fmt.Sprintf("%.0f %%", p.Stats.SuccessRate())))
+	}
+	return table
+}
+
+func (a *WordCardsApp) ExportChartButton(c Chart, fileName string) *widget.Button {
+	return widget.NewButton(core.T("stats.savePNG"), func() {
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, a.window)
+				return
+			}
+			if writer == nil {
+				return // cancelled
+			}
+			defer writer.Close()
+			if err := c.WritePNG(writer); err != nil {
+				dialog.ShowError(err, a.window)
+			}
+		}, a.window)
+		saveDialog.SetFileName(fileName)
+		saveDialog.Show()
+	})
+}
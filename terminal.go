@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"fancyCards/core"
+)
+
+// Practicing in a terminal, e.g. via SSH:
+//
+//	fancyCards practice de_fr -groups Gruppe1,Gruppe2 -reverse [-report bericht.html]
+//
+// An empty answer skips the card, ":q" or Ctrl-D ends the session.
+
+const quitCommand = ":q"
+
+const (
+	ansiReset  = "\033[0m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+	ansiBold   = "\033[1m"
+)
+
+type Terminal struct {
+	in    *bufio.Scanner
+	out   io.Writer
+	color bool
+}
+
+// NewTerminal only uses colors if stdout is a terminal and NO_COLOR isn't set
+func NewTerminal() Terminal {
+	color := os.Getenv("NO_COLOR") == ""
+	if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		color = false
+	}
+	return Terminal{in: bufio.NewScanner(os.Stdin), out: os.Stdout, color: color}
+}
+
+func (t Terminal) Colored(code string, text string) string {
+	if !t.color {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+func (t Terminal) Printf(format string, args ...any) {
+	fmt.Fprintf(t.out, format, args...)
+}
+
+// ReadLine returns false at the end of the input
+func (t Terminal) ReadLine() (string, bool) {
+	if !t.in.Scan() {
+		return "", false
+	}
+	return strings.TrimSpace(t.in.Text()), true
+}
+
+func successColor(success core.SuccessLevel) string {
+	switch success {
+	case core.Correct:
+		return ansiGreen
+	case core.Similar, core.Skipped:
+		return ansiYellow
+	}
+	return ansiRed
+}
+
+func RunPractice(args []string) error {
+	opts := Options{}
+	fs := newFlagSet("practice", &opts)
+	groups := fs.String("groups", "", core.T("flag.groups"))
+	reverse := fs.Bool("reverse", false, core.T("flag.reverse"))
+	report := fs.String("report", "", core.T("flag.report"))
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return core.NewError("cli.usage", "Usage", core.T("usage.practice"))
+	}
+
+	conf, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	lp, reversed, err := conf.FindLangPair(positional[0])
+	if err != nil {
+		return err
+	}
+	index, err := core.LoadCardIndex(conf.SavDir)
+	if err != nil {
+		return err
+	}
+	profile, err := openCommandProfile(conf, opts.profile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := profile.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	groupList := []string{}
+	for _, g := range strings.Split(*groups, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			groupList = append(groupList, g)
+		}
+	}
+	deck, err := core.LoadDeck(conf, index, lp, reversed != *reverse, groupList)
+	if err != nil {
+		return err
+	}
+	if err := index.Save(conf.SavDir); err != nil {
+		fmt.Fprintln(os.Stderr, core.T("cli.note"), err)
+	}
+	session := core.NewSession(profile, deck)
+	PracticeInTerminal(NewTerminal(), conf, session)
+	if *report == "" || len(session.Answers) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := core.WriteReport(&buf, conf, session); err != nil {
+		return err
+	}
+	if err := os.WriteFile(*report, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Println(core.T("report.saved", "File", *report))
+	return nil
+}
+
+// PracticeInTerminal asks cards until the input ends or the session length of the profile
+// is reached, every answer is saved right away
+func PracticeInTerminal(t Terminal, conf core.Config, session *core.Session) {
+	lp := session.Deck.AskedPair()
+	t.Printf("%s - %s\n", t.Colored(ansiBold, conf.GetLangPairAsString(lp)), core.T("terminal.cards", "Count", len(session.Deck.Cards)))
+	t.Printf("%s\n\n", core.T("terminal.help", "Quit", quitCommand))
+	for {
+		wc := session.Next()
+		t.Printf("%s: %s\n> ", conf.GetLangName(lp.Source), wc.Source)
+		word, ok := t.ReadLine()
+		if !ok || word == quitCommand {
+			t.Printf("\n")
+			break
+		}
+		e, err := session.Submit(word)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, core.T("save.failed", "Err", err))
+		}
+		t.Printf("%s %s => %s\n\n", t.Colored(successColor(e.Success), e.Result().Feedback()), wc.Source, wc.Target)
+		if session.Done() {
+			break
+		}
+	}
+	if st := session.Stats; st.Count > 0 {
+		t.Printf("%s\n", core.T("terminal.summary", "Count", st.Count, "Correct", st.Successes, "Similar", st.Similar,
+			"Wrong", st.Mistakes, "Skipped", st.Skipped, "Rate", fmt.Sprintf("%.0f", st.SuccessRate())))
+		t.Printf("%s\n", core.T("session.time", "Time", core.FormatDuration(session.Elapsed())))
+	}
+	if failed := session.FailedCards(); len(failed) > 0 {
+		t.Printf("%s\n", core.T("session.failed", "Count", len(failed)))
+		for _, wc := range failed {
+			t.Printf("  %s => %s\n", wc.Source, wc.Target)
+		}
+	}
+}
@@ -1,49 +1,83 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+
+	"fancyCards/core"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 )
 
 type WordCardsApp struct {
-	conf       CardsConfig
+	conf       core.Config
 	app        fyne.App
 	window     fyne.Window
 	mainMenu   *fyne.Container
-	rando      CardsRandomizer
-	statistics map[string]Stats
-	selectedLP LangPair
+	selectedLP core.LangPair
 	reverse    bool
+	cardIndex  *core.CardIndex
+	session    *core.Session
+	keyActions map[string]func() // of the practice view which is shown, see keyboard.go
+	stopTimer  chan struct{}     // ends the updates of the session progress, see sessionSummary.go
+
+	// the selected profile, one without a name which keeps everything in memory until then
+	profile    *core.Profile
+	saveFailed bool
 }
 
 // ******************************************************
 // INITIALIZE
 // ******************************************************
 
-func InitUI(conf CardsConfig) WordCardsApp {
+func InitUI(conf core.Config, opts Options) WordCardsApp {
 	fyneApp := app.New()
 	w := fyneApp.NewWindow("fancyCards")
 
-	application := WordCardsApp{conf: conf, app: fyneApp, window: w}
+	application := WordCardsApp{conf: conf, app: fyneApp, window: w, profile: core.InMemoryProfile()}
 	application.CreateMainMenu(conf)
-	err := application.InitializeStatistics()
 	application.ToMainMenu()
-	application.HandleError(err)
+	application.InstallKeys()
+	application.HandleError(application.InitializeCardIndex())
+	application.InitializeProfiles(opts.profile)
+	if opts.pair != "" && (application.profile.Name != "" || conf.SavDir == "") {
+		lp, reverse, err := conf.FindLangPair(opts.pair)
+		if err != nil {
+			application.HandleError(err)
+		} else {
+			application.OpenLangpairMenu(lp, reverse)
+		}
+	}
 
 	return application
 }
 
-func (a *WordCardsApp) CreateMainMenu(conf CardsConfig) {
+// AcquireLock warns if another instance uses the same profile
+func (a *WordCardsApp) AcquireLock() {
+	if err := a.profile.AcquireLock(); err != nil {
+		dialog.ShowInformation(core.T("dialog.note"), err.Error(), a.window)
+	}
+}
+
+// InitializeCardIndex reads the IDs the cards' history is stored under
+func (a *WordCardsApp) InitializeCardIndex() error {
+	idx, err := core.LoadCardIndex(a.conf.SavDir)
+	a.cardIndex = idx
+	return err
+}
+
+func (a *WordCardsApp) CreateMainMenu(conf core.Config) {
 	viewHeader := NewViewHeader("I <3 Wordcards")
 	a.mainMenu = container.NewVBox(
 		viewHeader,
 	)
 
-	for _, lp := range conf.langPairs {
+	for _, lp := range conf.LangPairs {
 		lpstr := conf.GetLangPairAsString(lp)
 		btn := widget.NewButton(lpstr, func() {
 			a.OpenLangpairMenu(lp, false)
@@ -64,7 +98,17 @@ func (a *WordCardsApp) CreateMainMenu(conf CardsConfig) {
 
 	}
 
-	a.mainMenu.Add(widget.NewButton("Anleitung", func() {
+	if a.profile.Name != "" {
+		a.mainMenu.Add(widget.NewButton(core.T("menu.profile", "Profile", a.profile.Name), func() {
+			a.ShowProfiles()
+		}))
+	}
+
+	a.mainMenu.Add(widget.NewButton(core.T("menu.lint"), func() {
+		a.ShowLintReport()
+	}))
+
+	a.mainMenu.Add(widget.NewButton(core.T("menu.instructions"), func() {
 		a.OpenInstructions()
 	}))
 }
@@ -74,11 +118,13 @@ func (a *WordCardsApp) CreateMainMenu(conf CardsConfig) {
 // ******************************************************
 
 func (a *WordCardsApp) ToMainMenu() {
+	a.keyActions = nil
+	a.StopTimer()
 	a.window.SetContent(a.mainMenu)
 }
 
 func (a *WordCardsApp) ReturnButton() *widget.Button {
-	return widget.NewButton("Zurück zum Startmenü", func() {
+	return widget.NewButton(core.T("button.mainMenu"), func() {
 		a.ToMainMenu()
 	})
 }
@@ -102,7 +148,7 @@ func (a *WordCardsApp) HandleErrorList(errorList []error) {
 		return
 	}
 
-	errorBox := container.NewVBox(NewViewHeader("Fehler!"))
+	errorBox := container.NewVBox(NewViewHeader(core.T("error.header")))
 
 	for _, err := range errorList {
 		errMsg := widget.NewLabel(err.Error())
@@ -113,44 +159,71 @@ func (a *WordCardsApp) HandleErrorList(errorList []error) {
 	a.window.SetContent(errorBox)
 }
 
+// SaveAll writes statistics and card statistics of the profile and the card index
+func (a *WordCardsApp) SaveAll() error {
+	return errors.Join(a.profile.Save(), a.cardIndex.Save(a.conf.SavDir))
+}
+
+// SaveProgress is called after each answer, so that a crash doesn't lose the session
+func (a *WordCardsApp) SaveProgress() {
+	a.ShowSaveError(a.SaveAll())
+}
+
+// ShowSaveError shows failed saves in a dialog, but only once until saving works again
+func (a *WordCardsApp) ShowSaveError(err error) {
+	if err == nil {
+		a.saveFailed = false
+		return
+	}
+	if !a.saveFailed {
+		dialog.ShowError(core.NewError("save.failed", "Err", err), a.window)
+	}
+	a.saveFailed = true
+}
+
 // ******************************************************
 // WORDCARDS
 // ******************************************************
 
-func (a *WordCardsApp) OpenLangpairMenu(lp LangPair, reverse bool) {
+func (a *WordCardsApp) OpenLangpairMenu(lp core.LangPair, reverse bool) {
 	a.selectedLP = lp
 	a.reverse = reverse
 
 	viewHeader := NewViewHeader(a.conf.GetLangPairAsString(a.GetSelectedLangPair()))
 
-	exerciseButton := widget.NewButton("Starten", func() {
-		cards, err := ReadCards(a.conf, lp, reverse, []string{})
-		a.HandleError(err)
-		if err == nil {
-			a.rando = NewRando(cards)
-			a.LoadRandomCard()
-		}
+	exerciseButton := widget.NewButton(core.T("button.start"), func() {
+		a.StartExercise([]string{})
 	})
 
 	lpMenu := container.NewVBox(viewHeader, exerciseButton)
 	if len(a.conf.GetGroups(lp)) > 0 {
-		toGroupsButton := widget.NewButton("Wörter-Gruppen auswählen", func() {
+		toGroupsButton := widget.NewButton(core.T("pair.selectGroups"), func() {
 			a.GroupSelection()
 		})
 		lpMenu.Add(toGroupsButton)
 	}
 
-	statsButton := widget.NewButton("Statistik ansehen", func() {
+	statsButton := widget.NewButton(core.T("pair.stats"), func() {
 		a.ShowStatSummary()
 	})
 
+	hardestButton := widget.NewButton(core.T("pair.hardest"), func() {
+		a.ShowHardestCards()
+	})
+
+	printButton := widget.NewButton(core.T("button.print"), func() {
+		a.PrintDialog()
+	})
+
 	lpMenu.Add(statsButton)
+	lpMenu.Add(hardestButton)
+	lpMenu.Add(printButton)
 	lpMenu.Add(a.ReturnButton())
 	a.window.SetContent(lpMenu)
 
 }
 
-func (a *WordCardsApp) GetSelectedLangPair() LangPair {
+func (a *WordCardsApp) GetSelectedLangPair() core.LangPair {
 	if a.reverse {
 		return a.selectedLP.Flip()
 	} else {
@@ -160,21 +233,16 @@ func (a *WordCardsApp) GetSelectedLangPair() LangPair {
 
 func (a *WordCardsApp) GroupSelection() {
 
-	viewHeader := NewViewHeader(a.conf.GetLangPairAsString(a.GetSelectedLangPair()) + " - Wörter-Gruppen auswählen")
+	viewHeader := NewViewHeader(a.conf.GetLangPairAsString(a.GetSelectedLangPair()) + " - " + core.T("pair.selectGroups"))
 
 	checkboxGroup := widget.NewCheckGroup(a.conf.GetGroups(a.selectedLP), func(strs []string) {})
 	checkboxGroup.Horizontal = true
 
-	exerciseButton := widget.NewButton("Starten", func() {
-		cards, err := ReadCards(a.conf, a.selectedLP, a.reverse, checkboxGroup.Selected)
-		a.HandleError(err)
-		if err == nil {
-			a.rando = NewRando(cards)
-			a.LoadRandomCard()
-		}
+	exerciseButton := widget.NewButton(core.T("button.start"), func() {
+		a.StartExercise(checkboxGroup.Selected)
 	})
 
-	backButton := widget.NewButton("Zurück", func() {
+	backButton := widget.NewButton(core.T("button.back"), func() {
 		a.OpenLangpairMenu(a.selectedLP, a.reverse)
 	})
 
@@ -182,81 +250,194 @@ func (a *WordCardsApp) GroupSelection() {
 	a.window.SetContent(groupsMenu)
 }
 
+func (a *WordCardsApp) LoadDeck(groups []string) (core.Deck, error) {
+	deck, err := core.LoadDeck(a.conf, a.cardIndex, a.selectedLP, a.reverse, groups)
+	if err != nil {
+		return deck, err
+	}
+	a.ShowSaveError(a.cardIndex.Save(a.conf.SavDir))
+	return deck, nil
+}
+
+func (a *WordCardsApp) StartExercise(groups []string) {
+	deck, err := a.LoadDeck(groups)
+	a.HandleError(err)
+	if err == nil {
+		a.session = core.NewSession(a.profile, deck)
+		a.LoadRandomCard()
+	}
+}
+
 func (a *WordCardsApp) LoadRandomCard() {
-	wc := a.rando.FetchRandomCard()
+	wc := a.session.Next()
 
-	textbox := widget.NewEntry()
+	textbox := newAnswerEntry(a)
 
-	lang := a.conf.GetLangName(a.GetSelectedLangPair().sourceLang)
-	inputWord := widget.NewLabel(fmt.Sprintf("%s: %s", lang, wc.sourceWord))
+	lang := a.conf.GetLangName(a.GetSelectedLangPair().Source)
+	inputWord := widget.NewLabel(fmt.Sprintf("%s: %s", lang, wc.Source))
+	hintLabel := widget.NewLabel("")
 
-	checkBtn := widget.NewButton("Prüfen", func() {
-		a.CheckCard(textbox.Text, wc)
-	})
+	check := func() { a.CheckCard(textbox.Text) }
+	skip := func() { a.CheckCard("") }
+	hint := func() { hintLabel.SetText(core.T("practice.hint", "Hint", a.session.Hint())) }
+	a.keyActions = map[string]func(){core.KeyCheck: check, core.KeyMenu: a.ToMainMenu, core.KeySkip: skip, core.KeyHint: hint}
+
+	checkBtn := widget.NewButton(core.T("button.check"), check)
+	skipBtn := widget.NewButton(a.KeyLabel(core.T("button.skip"), core.KeySkip), skip)
+	hintBtn := widget.NewButton(a.KeyLabel(core.T("button.hint"), core.KeyHint), hint)
 
 	cardsView := container.NewVBox(
+		a.SessionProgress(),
 		inputWord,
+		hintLabel,
 		textbox,
+		a.CharacterPalette(textbox),
 		checkBtn,
+		container.NewGridWithColumns(2, skipBtn, hintBtn),
 		a.ReturnButton(),
 	)
 
 	a.window.SetContent(cardsView)
+	a.window.Canvas().Focus(textbox)
 }
 
-func (a *WordCardsApp) CheckCard(word string, wc WordCard) {
-
-	feedbackLabel := widget.NewLabel("")
-	success := CheckInput(word, wc)
-	switch success {
-	case Wrong:
-		feedbackLabel.SetText("Falsch!")
-	case Similar:
-		feedbackLabel.SetText("Ähnlich:")
-	case Correct:
-		feedbackLabel.SetText("Richtig!")
-	case Skipped:
-		feedbackLabel.SetText("Übersprungen...")
-	}
-	a.IncrementCount(success)
+func (a *WordCardsApp) CheckCard(word string) {
+	wc := a.session.Current()
+	e, err := a.session.Submit(word)
+	a.ShowSaveError(err)
+	feedbackLabel := widget.NewLabel(e.Feedback())
 
-	continueBtn := widget.NewButton("Weiter", func() {
-		a.LoadRandomCard()
+	next := func() {
+		if a.session.Done() {
+			a.ShowSessionSummary()
+		} else {
+			a.LoadRandomCard()
+		}
+	}
+	continueBtn := widget.NewButton(core.T("button.continue"), next)
+	endBtn := widget.NewButton(core.T("button.endSession"), func() {
+		a.ShowSessionSummary()
 	})
+	a.keyActions = map[string]func(){core.KeyCheck: next, core.KeyMenu: a.ToMainMenu}
+
+	// only a wrong or similar answer can be marked as correct
+	correctBtn := widget.NewButton(a.KeyLabel(core.T("button.markCorrect"), core.KeyCorrect), nil)
+	correctBtn.OnTapped = func() {
+		e, err := a.session.MarkCorrect()
+		a.ShowSaveError(err)
+		feedbackLabel.SetText(e.Feedback())
+		correctBtn.Hide()
+		delete(a.keyActions, core.KeyCorrect)
+	}
+	if a.session.CanMarkCorrect() {
+		a.keyActions[core.KeyCorrect] = correctBtn.OnTapped
+	} else {
+		correctBtn.Hide()
+	}
 
-	correctSolution := widget.NewLabel(fmt.Sprintf("%s => %s", wc.sourceWord, wc.targetWord))
+	correctSolution := widget.NewLabel(fmt.Sprintf("%s => %s", wc.Source, wc.Target))
+
+	reportBtn := widget.NewButton(core.T("button.saveReport"), func() {
+		a.SaveReportDialog()
+	})
 
 	resultView := container.NewVBox(
+		a.SessionProgress(),
 		feedbackLabel,
 		correctSolution,
 		continueBtn,
+		correctBtn,
+		endBtn,
+		reportBtn,
 		a.ReturnButton(),
 	)
 
 	a.window.SetContent(resultView)
 }
 
+// ******************************************************
+// VALIDATION
+// ******************************************************
+
+func (a *WordCardsApp) ShowLintReport() {
+	report := core.ValidateDecks(a.conf)
+
+	viewHeader := NewViewHeader(core.T("menu.lint"))
+	issueList := container.NewVBox()
+	if len(report.Issues) == 0 {
+		issueList.Add(widget.NewLabel(core.T("lint.none")))
+	}
+	for _, issue := range report.Issues {
+		issueList.Add(widget.NewLabel(issue.Message))
+	}
+
+	statusLabel := widget.NewLabel(core.T("lint.count", "Count", len(report.Issues)))
+	saveButton := widget.NewButton(core.T("lint.saveJSON"), func() {
+		path, err := core.SaveLintReport(a.conf.SavDir, report)
+		if err != nil {
+			statusLabel.SetText(core.T("lint.saveFailed", "Err", err))
+			return
+		}
+		statusLabel.SetText(core.T("lint.saved", "File", path))
+	})
+
+	top := container.NewVBox(viewHeader, statusLabel)
+	bottom := container.NewVBox(saveButton, a.ReturnButton())
+	a.window.SetContent(container.NewBorder(top, bottom, nil, nil, container.NewVScroll(issueList)))
+}
+
 // ******************************************************
 // STATISTICS
 // ******************************************************
 
-func (a *WordCardsApp) ShowStatSummary() {
+func (a *WordCardsApp) ShowHardestCards() {
 	lp := a.GetSelectedLangPair()
-	statEvals := a.GetStatEvals(lp)
+	deck, err := a.LoadDeck([]string{})
+	if err != nil {
+		a.HandleError(err)
+		return
+	}
 
-	viewHeader := NewViewHeader(a.conf.GetLangPairAsString(lp))
-	statPage := container.NewVBox(
-		viewHeader,
-	)
-	if len(statEvals) == 0 {
-		statPage.Add(widget.NewLabel("Noch keine Statistik für diese Karten erfasst..."))
+	hardest := a.profile.HardestCards(deck.Cards, 15)
+	skipped := a.profile.MostSkippedCards(deck.Cards, 10)
+
+	viewHeader := NewViewHeader(a.conf.GetLangPairAsString(lp) + " - " + core.T("pair.hardest"))
+	rankingList := container.NewVBox(NewViewHeader(core.T("hardest.wrong")))
+	if len(hardest) == 0 {
+		rankingList.Add(widget.NewLabel(core.T("hardest.noWrong")))
+	}
+	for _, rc := range hardest {
+		rankingList.Add(widget.NewLabel(core.T("hardest.wrongCard", "Source", rc.Card.Source, "Target", rc.Card.Target,
+			"Wrong", rc.Stats.Wrong+rc.Stats.Skipped, "Attempts", rc.Stats.Attempts, "Similar", rc.Stats.Similar)))
+	}
+
+	rankingList.Add(NewViewHeader(core.T("hardest.skipped")))
+	if len(skipped) == 0 {
+		rankingList.Add(widget.NewLabel(core.T("hardest.noSkipped")))
+	}
+	for _, rc := range skipped {
+		rankingList.Add(widget.NewLabel(core.T("hardest.skippedCard", "Source", rc.Card.Source, "Target", rc.Card.Target,
+			"Skipped", rc.Stats.Skipped, "Attempts", rc.Stats.Attempts)))
+	}
+
+	practiceDeck := core.Deck{LangPair: deck.LangPair, Reverse: deck.Reverse, Cards: []core.Card{}, Characters: deck.Characters, Shortcuts: deck.Shortcuts}
+	for _, rc := range slices.Concat(hardest, skipped) {
+		if !slices.ContainsFunc(practiceDeck.Cards, func(wc core.Card) bool { return wc.StatsKey() == rc.Card.StatsKey() }) {
+			practiceDeck.Cards = append(practiceDeck.Cards, rc.Card)
+		}
 	}
-	for key, eval := range statEvals {
-		stringifyEval := fmt.Sprintf("%s - %d Karteikarten, %d richtig, %d falsch",
-			key, eval.Count, eval.Successes, eval.Mistakes)
-		statPage.Add(widget.NewLabel(stringifyEval))
+	practiceButton := widget.NewButton(core.T("hardest.practice"), func() {
+		a.session = core.NewSession(a.profile, practiceDeck)
+		a.LoadRandomCard()
+	})
+	if len(practiceDeck.Cards) == 0 {
+		practiceButton.Disable()
 	}
 
-	statPage.Add(a.ReturnButton())
-	a.window.SetContent(statPage)
+	backButton := widget.NewButton(core.T("button.back"), func() {
+		a.OpenLangpairMenu(a.selectedLP, a.reverse)
+	})
+
+	bottom := container.NewVBox(practiceButton, backButton, a.ReturnButton())
+	a.window.SetContent(container.NewBorder(viewHeader, bottom, nil, nil, container.NewVScroll(rankingList)))
 }
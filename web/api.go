@@ -0,0 +1,642 @@
+package web
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"fancyCards/core"
+)
+
+// The JSON API under /api is for other programs, e.g. a dashboard or a browser extension.
+// POST /api/login returns a token, which is sent as "Authorization: Bearer <token>".
+// Tokens aren't cookies, so the API needs no CSRF tokens and may be called from other
+// origins. openapi.json describes all endpoints.
+
+//go:embed openapi.json
+var openAPI []byte
+
+// maxRequestBody limits the size of a JSON request
+const maxRequestBody = 1 << 20
+
+type apiLogin struct {
+	profile  string
+	lastSeen time.Time
+}
+
+// apiPractice is a session started by POST /api/sessions
+type apiPractice struct {
+	id       string
+	profile  string
+	session  *core.Session
+	answer   *core.AnswerEvent // nil until the current card is answered
+	card     core.Card         // the card the answer belongs to
+	lastSeen time.Time
+}
+
+type apiHandlerFunc func(w http.ResponseWriter, r *http.Request, login *apiLogin)
+
+func (s *Server) apiRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("OPTIONS /api/", apiPreflight)
+	mux.HandleFunc("GET /api/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+	s.handleAPI(mux, "POST /api/login", false, s.apiLogin)
+	s.handleAPI(mux, "POST /api/logout", true, s.apiLogout)
+	s.handleAPI(mux, "GET /api/pairs", true, s.apiPairs)
+	s.handleAPI(mux, "GET /api/pairs/{pair}/cards", true, s.apiCards)
+	s.handleAPI(mux, "POST /api/pairs/{pair}/cards", true, s.editing(s.apiAddCard))
+	s.handleAPI(mux, "PUT /api/pairs/{pair}/cards/{id}", true, s.editing(s.apiUpdateCard))
+	s.handleAPI(mux, "DELETE /api/pairs/{pair}/cards/{id}", true, s.editing(s.apiDeleteCard))
+	s.handleAPI(mux, "POST /api/sessions", true, s.apiStartSession)
+	s.handleAPI(mux, "GET /api/sessions/{id}", true, s.apiGetSession)
+	s.handleAPI(mux, "POST /api/sessions/{id}/answer", true, s.apiAnswer)
+	s.handleAPI(mux, "POST /api/sessions/{id}/next", true, s.apiNext)
+	s.handleAPI(mux, "POST /api/sessions/{id}/retry", true, s.apiRetry)
+	s.handleAPI(mux, "GET /api/sessions/{id}/report", true, s.apiReport)
+	s.handleAPI(mux, "DELETE /api/sessions/{id}", true, s.apiEndSession)
+	s.handleAPI(mux, "GET /api/stats", true, s.apiStats)
+	s.handleAPI(mux, "/api/", false, func(w http.ResponseWriter, r *http.Request, login *apiLogin) {
+		writeError(w, http.StatusNotFound, core.NewError("api.unknownEndpoint"))
+	})
+}
+
+// handleAPI runs the handler with the server locked and checks the bearer token
+func (s *Server) handleAPI(mux *http.ServeMux, pattern string, needsLogin bool, h apiHandlerFunc) {
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.expireAPI()
+		login := s.apiLogins[bearerToken(r)]
+		if needsLogin && login == nil {
+			writeError(w, http.StatusUnauthorized, core.NewError("api.loginFirst"))
+			return
+		}
+		if login != nil {
+			login.lastSeen = time.Now()
+		}
+		h(w, r, login)
+	})
+}
+
+func apiPreflight(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+	w.Header().Set("Access-Control-Max-Age", "86400")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// expireAPI forgets logins and practice sessions which weren't used for a while
+func (s *Server) expireAPI() {
+	now := time.Now()
+	for token, login := range s.apiLogins {
+		if now.Sub(login.lastSeen) > sessionTimeout {
+			delete(s.apiLogins, token)
+		}
+	}
+	for id, ap := range s.practices {
+		if now.Sub(ap.lastSeen) > sessionTimeout {
+			delete(s.practices, id)
+		}
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+type apiError struct {
+	Error string `json:"error"`
+	ID    string `json:"id,omitempty"` // the message ID, independent of the language
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, apiError{Error: err.Error(), ID: core.ErrorID(err)})
+}
+
+// readJSON decodes the request body, on errors it answers the request and returns false
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, core.NewError("api.invalidJSON", "Err", err))
+		return false
+	}
+	return true
+}
+
+// ******************************************************
+// LOGIN
+// ******************************************************
+
+type apiLoginRequest struct {
+	Profile string `json:"profile"`
+	PIN     string `json:"pin,omitempty"`
+}
+
+type apiLoginResponse struct {
+	Token   string `json:"token"`
+	Profile string `json:"profile"`
+}
+
+func (s *Server) apiLogin(w http.ResponseWriter, r *http.Request, _ *apiLogin) {
+	req := apiLoginRequest{}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if !slices.Contains(core.ListProfiles(s.conf.SavDir), req.Profile) {
+		writeError(w, http.StatusUnauthorized, core.NewError("api.unknownProfile"))
+		return
+	}
+	prefs := core.LoadPreferences(core.ProfileDir(s.conf.SavDir, req.Profile))
+	if wait, ok := s.checkPIN(r, req.Profile, prefs, req.PIN); wait > 0 {
+		w.Header().Set("Retry-After", retryAfter(wait))
+		writeError(w, http.StatusTooManyRequests, core.NewError("login.tooManyAttempts", "Seconds", retryAfter(wait)))
+		return
+	} else if !ok {
+		writeError(w, http.StatusUnauthorized, core.NewError("login.wrongPIN"))
+		return
+	}
+	s.openProfile(req.Profile).Prefs = prefs
+	token := newToken()
+	s.apiLogins[token] = &apiLogin{profile: req.Profile, lastSeen: time.Now()}
+	writeJSON(w, http.StatusOK, apiLoginResponse{Token: token, Profile: req.Profile})
+}
+
+func (s *Server) apiLogout(w http.ResponseWriter, r *http.Request, login *apiLogin) {
+	delete(s.apiLogins, bearerToken(r))
+	for id, ap := range s.practices {
+		if ap.profile == login.profile && !s.loggedIn(ap.profile) {
+			delete(s.practices, id)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loggedIn tells whether another token of the profile is still valid
+func (s *Server) loggedIn(profile string) bool {
+	for _, login := range s.apiLogins {
+		if login.profile == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// ******************************************************
+// PAIRS AND CARDS
+// ******************************************************
+
+type apiPair struct {
+	Pair   string   `json:"pair"` // in the direction it is asked, e.g. "fr_de"
+	Name   string   `json:"name"`
+	Deck   string   `json:"deck"` // as listed in the file list, e.g. "de_fr"
+	Groups []string `json:"groups"`
+}
+
+type apiCard struct {
+	ID      string `json:"id"`
+	File    string `json:"file"`
+	Source  string `json:"source"`
+	Target  string `json:"target"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// apiCardText is the body of POST and PUT on cards, File is only used by POST
+type apiCardText struct {
+	File    string `json:"file,omitempty"`
+	Source  string `json:"source"`
+	Target  string `json:"target"`
+	Comment string `json:"comment,omitempty"`
+}
+
+func newAPICard(wc core.Card) apiCard {
+	return apiCard{ID: wc.ID, File: wc.FileName, Source: wc.Source, Target: wc.Target, Comment: wc.SourceComment}
+}
+
+func (s *Server) apiPairs(w http.ResponseWriter, r *http.Request, _ *apiLogin) {
+	pairs := []apiPair{}
+	for _, link := range s.pairLinks() {
+		lp, _, err := s.conf.FindLangPair(link.Pair)
+		if err != nil {
+			continue
+		}
+		groups := s.conf.GetGroups(lp)
+		if groups == nil {
+			groups = []string{}
+		}
+		pairs = append(pairs, apiPair{Pair: link.Pair, Name: link.Name, Deck: lp.ToString(), Groups: groups})
+	}
+	writeJSON(w, http.StatusOK, pairs)
+}
+
+// loadDeck loads the deck of the pair in the path, on errors it answers the request and returns false
+func (s *Server) loadDeck(w http.ResponseWriter, r *http.Request, groups []string) (core.Deck, bool) {
+	lp, reversed, err := s.conf.FindLangPair(r.PathValue("pair"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return core.Deck{}, false
+	}
+	deck, err := core.LoadDeck(s.conf, s.cardIndex, lp, reversed, groups)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return core.Deck{}, false
+	}
+	if err := s.cardIndex.Save(s.conf.SavDir); err != nil {
+		log.Println(err)
+	}
+	return deck, true
+}
+
+func (s *Server) apiCards(w http.ResponseWriter, r *http.Request, _ *apiLogin) {
+	deck, ok := s.loadDeck(w, r, r.URL.Query()["group"])
+	if !ok {
+		return
+	}
+	cards := []apiCard{}
+	for _, wc := range deck.Cards {
+		cards = append(cards, newAPICard(wc))
+	}
+	writeJSON(w, http.StatusOK, cards)
+}
+
+// editing only lets the handler change the deck files if the server allows it and the profile
+// has a PIN. Without a PIN anybody in the network could log in and rewrite the files.
+func (s *Server) editing(h apiHandlerFunc) apiHandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, login *apiLogin) {
+		if !s.AllowEditing {
+			writeError(w, http.StatusForbidden, core.NewError("api.editingDisabled"))
+			return
+		}
+		if core.LoadPreferences(core.ProfileDir(s.conf.SavDir, login.profile)).PIN == "" {
+			writeError(w, http.StatusForbidden, core.NewError("api.editingNeedsPIN"))
+			return
+		}
+		h(w, r, login)
+	}
+}
+
+func (s *Server) apiAddCard(w http.ResponseWriter, r *http.Request, _ *apiLogin) {
+	lp, reversed, err := s.conf.FindLangPair(r.PathValue("pair"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	req := apiCardText{}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	text := core.CardText{Source: req.Source, Target: req.Target, Comment: req.Comment}
+	added, err := core.AddCard(s.conf, lp, req.File, reversed, text)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	// loading the deck again gives the card the ID of its history, if it had one before
+	deck, ok := s.loadDeck(w, r, []string{})
+	if !ok {
+		return
+	}
+	for _, wc := range deck.Cards {
+		if wc.FileName == added.FileName && wc.Row == added.Row {
+			writeJSON(w, http.StatusCreated, newAPICard(wc))
+			return
+		}
+	}
+	writeError(w, http.StatusInternalServerError, core.NewError("api.addedCardMissing"))
+}
+
+// findCard looks up the card in the path, on errors it answers the request and returns false
+func (s *Server) findCard(w http.ResponseWriter, r *http.Request) (core.Card, bool) {
+	deck, ok := s.loadDeck(w, r, []string{})
+	if !ok {
+		return core.Card{}, false
+	}
+	id := r.PathValue("id")
+	for _, wc := range deck.Cards {
+		if wc.ID == id {
+			return wc, true
+		}
+	}
+	writeError(w, http.StatusNotFound, core.NewError("api.unknownCard", "Card", id))
+	return core.Card{}, false
+}
+
+func (s *Server) apiUpdateCard(w http.ResponseWriter, r *http.Request, _ *apiLogin) {
+	wc, ok := s.findCard(w, r)
+	if !ok {
+		return
+	}
+	req := apiCardText{}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	text := core.CardText{Source: req.Source, Target: req.Target, Comment: req.Comment}
+	if err := text.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := core.UpdateCard(s.conf, wc, text); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	// loading the deck again gives the card its ID, which stays the same unless both texts changed
+	deck, ok := s.loadDeck(w, r, []string{})
+	if !ok {
+		return
+	}
+	for _, updated := range deck.Cards {
+		if updated.FileName == wc.FileName && updated.Row == wc.Row {
+			writeJSON(w, http.StatusOK, newAPICard(updated))
+			return
+		}
+	}
+	writeError(w, http.StatusInternalServerError, core.NewError("api.changedCardMissing"))
+}
+
+func (s *Server) apiDeleteCard(w http.ResponseWriter, r *http.Request, _ *apiLogin) {
+	wc, ok := s.findCard(w, r)
+	if !ok {
+		return
+	}
+	if err := core.DeleteCard(s.conf, wc); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ******************************************************
+// PRACTICING
+// ******************************************************
+
+type apiStartRequest struct {
+	Pair   string   `json:"pair"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+type apiSession struct {
+	ID       string     `json:"id"`
+	Pair     string     `json:"pair"`
+	Cards    int        `json:"cards"`
+	Card     apiCard    `json:"card"`
+	Answered bool       `json:"answered"`
+	Stats    core.Stats `json:"stats"`
+	Limit    apiLimit   `json:"limit"`
+	Progress float64    `json:"progress"` // 0 to 1, 0 without a limit
+	Done     bool       `json:"done"`     // the limit is reached, next gives no more cards
+	Failed   []apiCard  `json:"failed"`   // cards which weren't answered correctly, for the summary
+}
+
+type apiLimit struct {
+	Cards   int `json:"cards"`   // 0: no limit
+	Minutes int `json:"minutes"` // 0: no limit
+}
+
+type apiAnswerRequest struct {
+	Answer string `json:"answer"`
+}
+
+type apiFeedback struct {
+	Success  core.SuccessLevel `json:"success"`
+	Mistake  core.MistakeKind  `json:"mistake,omitempty"`
+	Feedback string            `json:"feedback"`
+	Answer   string            `json:"answer"`
+	Card     apiCard           `json:"card"`
+	Stats    core.Stats        `json:"stats"`
+}
+
+// newAPISession hides the answer to the current card until it was answered
+func newAPISession(ap *apiPractice) apiSession {
+	card := newAPICard(ap.session.Current())
+	if ap.answer == nil {
+		card.Target = ""
+	}
+	failed := []apiCard{}
+	for _, wc := range ap.session.FailedCards() {
+		failed = append(failed, newAPICard(wc))
+	}
+	return apiSession{
+		ID:       ap.id,
+		Pair:     ap.session.Deck.AskedPair().ToString(),
+		Cards:    len(ap.session.Deck.Cards),
+		Card:     card,
+		Answered: ap.answer != nil,
+		Stats:    ap.session.Stats,
+		Limit:    apiLimit{Cards: ap.session.Limit.Cards, Minutes: int(ap.session.Limit.Duration / time.Minute)},
+		Progress: ap.session.Progress(),
+		Done:     ap.session.Done(),
+		Failed:   failed,
+	}
+}
+
+// practice returns the practice session in the path, on errors it answers the request and returns nil
+func (s *Server) practice(w http.ResponseWriter, r *http.Request, login *apiLogin) *apiPractice {
+	ap, ok := s.practices[r.PathValue("id")]
+	if !ok || ap.profile != login.profile {
+		writeError(w, http.StatusNotFound, core.NewError("api.unknownSession"))
+		return nil
+	}
+	ap.lastSeen = time.Now()
+	return ap
+}
+
+func (s *Server) apiStartSession(w http.ResponseWriter, r *http.Request, login *apiLogin) {
+	req := apiStartRequest{}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	lp, reversed, err := s.conf.FindLangPair(req.Pair)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	groups := req.Groups
+	if groups == nil {
+		groups = []string{}
+	}
+	deck, err := core.LoadDeck(s.conf, s.cardIndex, lp, reversed, groups)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.cardIndex.Save(s.conf.SavDir); err != nil {
+		log.Println(err)
+	}
+	s.addPractice(w, login, core.NewSession(s.openProfile(login.profile), deck))
+}
+
+// addPractice draws the first card of a new session and sends it
+func (s *Server) addPractice(w http.ResponseWriter, login *apiLogin, session *core.Session) {
+	ap := &apiPractice{id: newToken(), profile: login.profile, session: session, lastSeen: time.Now()}
+	ap.session.Next()
+	s.practices[ap.id] = ap
+	writeJSON(w, http.StatusCreated, newAPISession(ap))
+}
+
+func (s *Server) apiGetSession(w http.ResponseWriter, r *http.Request, login *apiLogin) {
+	if ap := s.practice(w, r, login); ap != nil {
+		writeJSON(w, http.StatusOK, newAPISession(ap))
+	}
+}
+
+func (s *Server) apiAnswer(w http.ResponseWriter, r *http.Request, login *apiLogin) {
+	ap := s.practice(w, r, login)
+	if ap == nil {
+		return
+	}
+	if ap.answer != nil {
+		writeError(w, http.StatusConflict, core.NewError("api.alreadyAnswered"))
+		return
+	}
+	req := apiAnswerRequest{}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	card := ap.session.Current()
+	e, err := ap.session.Submit(req.Answer)
+	if err != nil {
+		log.Print(core.T("serve.saveFailed", "Profile", ap.profile, "Err", err))
+	}
+	ap.answer = &e
+	ap.card = card
+	writeJSON(w, http.StatusOK, apiFeedback{
+		Success:  e.Success,
+		Mistake:  e.Mistake,
+		Feedback: e.Result().Feedback(),
+		Answer:   e.Input,
+		Card:     newAPICard(card),
+		Stats:    ap.session.Stats,
+	})
+}
+
+func (s *Server) apiNext(w http.ResponseWriter, r *http.Request, login *apiLogin) {
+	ap := s.practice(w, r, login)
+	if ap == nil {
+		return
+	}
+	if ap.session.Done() {
+		writeError(w, http.StatusConflict, core.NewError("api.sessionDone"))
+		return
+	}
+	ap.session.Next()
+	ap.answer = nil
+	writeJSON(w, http.StatusOK, newAPISession(ap))
+}
+
+// apiRetry starts a session with the cards of this one which weren't known
+func (s *Server) apiRetry(w http.ResponseWriter, r *http.Request, login *apiLogin) {
+	ap := s.practice(w, r, login)
+	if ap == nil {
+		return
+	}
+	if len(ap.session.FailedCards()) == 0 {
+		writeError(w, http.StatusConflict, core.NewError("api.nothingToRetry"))
+		return
+	}
+	s.addPractice(w, login, ap.session.RetryFailed())
+}
+
+// apiReport sends the HTML report of the session, errors are JSON as everywhere in the API
+func (s *Server) apiReport(w http.ResponseWriter, r *http.Request, login *apiLogin) {
+	ap := s.practice(w, r, login)
+	if ap == nil {
+		return
+	}
+	if len(ap.session.Answers) == 0 {
+		writeError(w, http.StatusConflict, core.NewError("report.empty"))
+		return
+	}
+	writeReport(w, s.conf, ap.session)
+}
+
+func (s *Server) apiEndSession(w http.ResponseWriter, r *http.Request, login *apiLogin) {
+	if ap := s.practice(w, r, login); ap != nil {
+		delete(s.practices, ap.id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ******************************************************
+// STATISTICS
+// ******************************************************
+
+type apiPeriod struct {
+	Start string `json:"start"` // yyyy-mm-dd
+	core.Stats
+}
+
+type apiPairStats struct {
+	Pair    string      `json:"pair"`
+	Periods []apiPeriod `json:"periods"` // only periods with answers
+}
+
+type apiStatsResponse struct {
+	Period        string         `json:"period"`
+	CurrentStreak int            `json:"currentStreak"`
+	LongestStreak int            `json:"longestStreak"`
+	Pairs         []apiPairStats `json:"pairs"`
+}
+
+func (s *Server) apiStats(w http.ResponseWriter, r *http.Request, login *apiLogin) {
+	period := r.URL.Query().Get("period")
+	switch period {
+	case "":
+		period = "monthly"
+	case "monthly", "daily":
+	default:
+		writeError(w, http.StatusBadRequest, core.NewError("api.invalidPeriod"))
+		return
+	}
+	links := s.pairLinks()
+	if pair := r.URL.Query().Get("pair"); pair != "" {
+		links = slices.DeleteFunc(links, func(link pairLink) bool { return link.Pair != pair })
+		if len(links) == 0 {
+			writeError(w, http.StatusNotFound, core.NewError("api.unknownPair", "Pair", pair))
+			return
+		}
+	}
+
+	p := s.openProfile(login.profile)
+	today := time.Now()
+	resp := apiStatsResponse{Period: period, Pairs: []apiPairStats{}}
+	resp.CurrentStreak, resp.LongestStreak = core.CalculateStreaks(p.AllDailyStats(), today)
+	for _, link := range links {
+		lp, reversed, err := s.conf.FindLangPair(link.Pair)
+		if err != nil {
+			continue
+		}
+		if reversed {
+			lp = lp.Flip()
+		}
+		ps := apiPairStats{Pair: link.Pair, Periods: []apiPeriod{}}
+		for _, sp := range core.StatsSeries(p.DailyStats(lp), time.Time{}, today, period == "monthly") {
+			if sp.Stats.Count > 0 {
+				ps.Periods = append(ps.Periods, apiPeriod{Start: sp.Start.Format(core.DayLayout), Stats: sp.Stats})
+			}
+		}
+		resp.Pairs = append(resp.Pairs, ps)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,349 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"fancyCards/core"
+)
+
+type page struct {
+	Title   string
+	CSRF    string
+	Profile string
+	Error   string
+	Refresh string // content of a refresh meta tag, e.g. "60;url=/summary"
+	Data    any
+}
+
+type pairLink struct {
+	Pair string // in the direction it is asked, e.g. "fr_de"
+	Name string
+}
+
+func (s *Server) render(w http.ResponseWriter, ws *webSession, name string, p page) {
+	p.CSRF = ws.csrf
+	p.Profile = ws.profile
+	var buf bytes.Buffer
+	if err := s.pages[name].Execute(&buf, p); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'; frame-ancestors 'none'")
+	w.Write(buf.Bytes())
+}
+
+// pairLinks lists the language pairs like the main menu of the app, with reverse directions
+func (s *Server) pairLinks() []pairLink {
+	links := []pairLink{}
+	for _, lp := range s.conf.LangPairs {
+		links = append(links, pairLink{Pair: lp.ToString(), Name: s.conf.GetLangPairAsString(lp)})
+		if !s.conf.LangPairExists(lp.Flip()) {
+			links = append(links, pairLink{Pair: lp.Flip().ToString(), Name: s.conf.GetLangPairAsString(lp.Flip())})
+		}
+	}
+	return links
+}
+
+// ******************************************************
+// LOGIN
+// ******************************************************
+
+func (s *Server) loginPage(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	s.render(w, ws, "login", page{Title: core.T("login.title"), Data: core.ListProfiles(s.conf.SavDir)})
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	name := r.PostFormValue("profile")
+	profiles := core.ListProfiles(s.conf.SavDir)
+	if !slices.Contains(profiles, name) {
+		s.render(w, ws, "login", page{Title: core.T("login.title"), Error: core.T("login.chooseProfile"), Data: profiles})
+		return
+	}
+	// read again, the PIN may have been changed in the app
+	prefs := core.LoadPreferences(core.ProfileDir(s.conf.SavDir, name))
+	if wait, ok := s.checkPIN(r, name, prefs, r.PostFormValue("pin")); !ok {
+		msg := core.T("login.wrongPIN")
+		if wait > 0 {
+			w.Header().Set("Retry-After", retryAfter(wait))
+			msg = core.T("login.tooManyAttempts", "Seconds", retryAfter(wait))
+		}
+		s.render(w, ws, "login", page{Title: core.T("login.title"), Error: msg, Data: profiles})
+		return
+	}
+	s.openProfile(name).Prefs = prefs
+	s.renewSession(w, ws)
+	ws.profile = name
+	ws.practice = nil
+	ws.answer = nil
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	s.renewSession(w, ws)
+	ws.profile = ""
+	ws.practice = nil
+	ws.answer = nil
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// ******************************************************
+// PRACTICING
+// ******************************************************
+
+func (s *Server) menuPage(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	s.render(w, ws, "menu", page{Title: core.T("menu.pairs"), Data: s.pairLinks()})
+}
+
+type pairData struct {
+	pairLink
+	Groups []string
+}
+
+func (s *Server) pairPage(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	lp, reversed, err := s.conf.FindLangPair(r.PathValue("pair"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	asked := lp
+	if reversed {
+		asked = lp.Flip()
+	}
+	data := pairData{pairLink: pairLink{Pair: asked.ToString(), Name: s.conf.GetLangPairAsString(asked)}, Groups: s.conf.GetGroups(lp)}
+	s.render(w, ws, "pair", page{Title: data.Name, Data: data})
+}
+
+func (s *Server) start(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	lp, reversed, err := s.conf.FindLangPair(r.PathValue("pair"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	deck, err := core.LoadDeck(s.conf, s.cardIndex, lp, reversed, r.PostForm["group"])
+	if err != nil {
+		s.render(w, ws, "menu", page{Title: core.T("menu.pairs"), Error: err.Error(), Data: s.pairLinks()})
+		return
+	}
+	if err := s.cardIndex.Save(s.conf.SavDir); err != nil {
+		log.Println(err)
+	}
+	ws.practice = core.NewSession(s.openProfile(ws.profile), deck)
+	ws.practice.Next()
+	ws.answer = nil
+	http.Redirect(w, r, "/card", http.StatusSeeOther)
+}
+
+// progressData shows how far a session with a limit is, like the progress bar of the app
+type progressData struct {
+	Limited   bool
+	Progress  float64
+	LimitText string
+}
+
+func newProgressData(session *core.Session) progressData {
+	return progressData{Limited: session.Limit != core.SessionLimit{}, Progress: session.Progress(), LimitText: session.LimitText()}
+}
+
+// summaryRefresh shows the summary when the minutes of the session are up,
+// even if the page is left open without an answer
+func summaryRefresh(session *core.Session) string {
+	if session.Limit.Duration == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d;url=/summary", int(session.TimeLeft().Seconds())+1)
+}
+
+type cardData struct {
+	progressData
+	Name  string
+	Lang  string
+	Card  core.Card
+	Stats core.Stats
+}
+
+func (s *Server) cardPage(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	if ws.practice == nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	asked := ws.practice.Deck.AskedPair()
+	data := cardData{
+		progressData: newProgressData(ws.practice),
+		Name:         s.conf.GetLangPairAsString(asked),
+		Lang:         s.conf.GetLangName(asked.Source),
+		Card:         ws.practice.Current(),
+		Stats:        ws.practice.Stats,
+	}
+	s.render(w, ws, "card", page{Title: data.Name, Refresh: summaryRefresh(ws.practice), Data: data})
+}
+
+func (s *Server) check(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	switch {
+	case ws.practice == nil:
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	case ws.answer != nil:
+		// sent twice, e.g. by reloading the page
+		http.Redirect(w, r, "/feedback", http.StatusSeeOther)
+		return
+	}
+	card := ws.practice.Current()
+	e, err := ws.practice.Submit(r.PostFormValue("answer"))
+	if err != nil {
+		log.Print(core.T("serve.saveFailed", "Profile", ws.profile, "Err", err))
+	}
+	ws.answer = &e
+	ws.card = card
+	http.Redirect(w, r, "/feedback", http.StatusSeeOther)
+}
+
+type feedbackData struct {
+	progressData
+	Feedback string
+	Class    string // "correct", "similar" or "wrong"
+	Input    string
+	Card     core.Card
+	Stats    core.Stats
+}
+
+func (s *Server) feedbackPage(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	if ws.practice == nil || ws.answer == nil {
+		http.Redirect(w, r, "/card", http.StatusSeeOther)
+		return
+	}
+	class := "wrong"
+	switch ws.answer.Success {
+	case core.Correct:
+		class = "correct"
+	case core.Similar, core.Skipped:
+		class = "similar"
+	}
+	data := feedbackData{
+		progressData: newProgressData(ws.practice),
+		Feedback:     ws.answer.Result().Feedback(),
+		Class:        class,
+		Input:        ws.answer.Input,
+		Card:         ws.card,
+		Stats:        ws.practice.Stats,
+	}
+	s.render(w, ws, "feedback", page{Title: data.Feedback, Refresh: summaryRefresh(ws.practice), Data: data})
+}
+
+// next shows the next card or, if the session reached its limit, the summary
+func (s *Server) next(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	if ws.practice == nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	if ws.practice.Done() {
+		http.Redirect(w, r, "/summary", http.StatusSeeOther)
+		return
+	}
+	ws.practice.Next()
+	ws.answer = nil
+	http.Redirect(w, r, "/card", http.StatusSeeOther)
+}
+
+type summaryData struct {
+	Rate   string
+	Stats  core.Stats
+	Time   string
+	Failed []core.Card
+}
+
+// summaryPage ends the session like the summary of the app: the result and the cards
+// which weren't known, with a button to retry them
+func (s *Server) summaryPage(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	if ws.practice == nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	data := summaryData{
+		Rate:   fmt.Sprintf("%.0f", ws.practice.Stats.SuccessRate()),
+		Stats:  ws.practice.Stats,
+		Time:   core.FormatDuration(ws.practice.Elapsed()),
+		Failed: ws.practice.FailedCards(),
+	}
+	s.render(w, ws, "summary", page{Title: core.T("session.summary"), Data: data})
+}
+
+// retry practices the cards of the session which weren't known once more
+func (s *Server) retry(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	if ws.practice == nil || len(ws.practice.FailedCards()) == 0 {
+		http.Redirect(w, r, "/summary", http.StatusSeeOther)
+		return
+	}
+	ws.practice = ws.practice.RetryFailed()
+	ws.practice.Next()
+	ws.answer = nil
+	http.Redirect(w, r, "/card", http.StatusSeeOther)
+}
+
+// report sends the answers of the session as a file to save or print
+func (s *Server) report(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	if ws.practice == nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	writeReport(w, s.conf, ws.practice)
+}
+
+func writeReport(w http.ResponseWriter, conf core.Config, session *core.Session) {
+	var buf bytes.Buffer
+	if err := core.WriteReport(&buf, conf, session); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	name := fmt.Sprintf("%s-%s-%s.html", core.T("report.fileName"), session.Deck.AskedPair().ToString(), session.Started.Format("2006-01-02-1504"))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	w.Write(buf.Bytes())
+}
+
+// ******************************************************
+// STATISTICS
+// ******************************************************
+
+type pairStats struct {
+	Name    string
+	Periods []core.StatPeriod // months with answers
+}
+
+type statsData struct {
+	Current int
+	Longest int
+	Pairs   []pairStats
+}
+
+func (s *Server) statsPage(w http.ResponseWriter, r *http.Request, ws *webSession) {
+	p := s.openProfile(ws.profile)
+	today := time.Now()
+	data := statsData{}
+	data.Current, data.Longest = core.CalculateStreaks(p.AllDailyStats(), today)
+	for _, link := range s.pairLinks() {
+		lp, reversed, err := s.conf.FindLangPair(link.Pair)
+		if err != nil {
+			continue
+		}
+		if reversed {
+			lp = lp.Flip()
+		}
+		ps := pairStats{Name: link.Name}
+		for _, period := range core.StatsSeries(p.DailyStats(lp), time.Time{}, today, true) {
+			if period.Stats.Count > 0 {
+				ps.Periods = append(ps.Periods, period)
+			}
+		}
+		if len(ps.Periods) > 0 {
+			data.Pairs = append(data.Pairs, ps)
+		}
+	}
+	s.render(w, ws, "stats", page{Title: core.T("stats.header"), Data: data})
+}
@@ -0,0 +1,274 @@
+// Package web serves fancyCards as HTML pages, e.g. for practicing on a phone in the home network.
+// The pages don't load anything from the internet, so they work without internet access.
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"fancyCards/core"
+)
+
+// Every visitor gets a session cookie right away. Each form carries the session's
+// CSRF token, a POST without the matching token is rejected.
+
+const (
+	sessionCookie  = "fancycards"
+	sessionTimeout = 12 * time.Hour
+)
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+// the templates take their texts from the catalogs of core, e.g. {{t "button.check"}}
+var templateFuncs = template.FuncMap{"t": core.T, "lang": core.Language}
+
+type Server struct {
+	// AllowEditing lets the API change the deck files, only for profiles with a PIN
+	AllowEditing bool
+
+	conf      core.Config
+	pages     map[string]*template.Template
+	mu        sync.Mutex // profiles and sessions are not safe for concurrent use
+	cardIndex *core.CardIndex
+	profiles  map[string]*core.Profile
+	sessions  map[string]*webSession
+	apiLogins map[string]*apiLogin
+	practices map[string]*apiPractice
+	failures  map[string]*loginFailures // by profile and by address, see checkPIN
+}
+
+type webSession struct {
+	id       string
+	csrf     string
+	profile  string // empty until logged in
+	practice *core.Session
+	answer   *core.AnswerEvent // shown on the feedback page
+	card     core.Card         // the card the answer belongs to
+	lastSeen time.Time
+}
+
+type handlerFunc func(w http.ResponseWriter, r *http.Request, ws *webSession)
+
+func NewServer(conf core.Config) (*Server, error) {
+	s := &Server{
+		conf:      conf,
+		pages:     map[string]*template.Template{},
+		profiles:  map[string]*core.Profile{},
+		sessions:  map[string]*webSession{},
+		apiLogins: map[string]*apiLogin{},
+		practices: map[string]*apiPractice{},
+		failures:  map[string]*loginFailures{},
+	}
+	if conf.SavDir == "" {
+		return nil, core.NewError("config.noSavDir")
+	}
+	if err := core.MigrateToProfiles(conf.SavDir); err != nil {
+		return nil, err
+	}
+	index, err := core.LoadCardIndex(conf.SavDir)
+	if err != nil {
+		return nil, err
+	}
+	s.cardIndex = index
+	for _, name := range []string{"login", "menu", "pair", "card", "feedback", "summary", "stats"} {
+		page, err := template.New("layout.html").Funcs(templateFuncs).ParseFS(templateFiles, "templates/layout.html", "templates/"+name+".html")
+		if err != nil {
+			return nil, err
+		}
+		s.pages[name] = page
+	}
+	return s, nil
+}
+
+// Handler returns the routes of the pages and of the API, see api.go
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	s.handle(mux, "GET /login", false, s.loginPage)
+	s.handle(mux, "POST /login", false, s.login)
+	s.handle(mux, "POST /logout", true, s.logout)
+	s.handle(mux, "GET /{$}", true, s.menuPage)
+	s.handle(mux, "GET /pair/{pair}", true, s.pairPage)
+	s.handle(mux, "POST /pair/{pair}/start", true, s.start)
+	s.handle(mux, "GET /card", true, s.cardPage)
+	s.handle(mux, "POST /card", true, s.check)
+	s.handle(mux, "GET /feedback", true, s.feedbackPage)
+	s.handle(mux, "POST /next", true, s.next)
+	s.handle(mux, "GET /summary", true, s.summaryPage)
+	s.handle(mux, "POST /retry", true, s.retry)
+	s.handle(mux, "GET /report", true, s.report)
+	s.handle(mux, "GET /stats", true, s.statsPage)
+	s.apiRoutes(mux)
+	return mux
+}
+
+// Close saves and closes all profiles which were used
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	errorList := []error{s.cardIndex.Save(s.conf.SavDir)}
+	for name, p := range s.profiles {
+		errorList = append(errorList, p.Close())
+		delete(s.profiles, name)
+	}
+	return errors.Join(errorList...)
+}
+
+// ******************************************************
+// SESSIONS
+// ******************************************************
+
+// handle runs the handler with the server locked, checks the CSRF token of a POST
+// and sends visitors who aren't logged in to the login page
+func (s *Server) handle(mux *http.ServeMux, pattern string, needsLogin bool, h handlerFunc) {
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		ws := s.session(w, r)
+		if r.Method == http.MethodPost && subtle.ConstantTimeCompare([]byte(r.PostFormValue("csrf")), []byte(ws.csrf)) != 1 {
+			http.Error(w, core.T("serve.invalidForm"), http.StatusForbidden)
+			return
+		}
+		if needsLogin && ws.profile == "" {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		h(w, r, ws)
+	})
+}
+
+// session returns the session of the cookie or starts a new one
+func (s *Server) session(w http.ResponseWriter, r *http.Request) *webSession {
+	now := time.Now()
+	for id, ws := range s.sessions {
+		if now.Sub(ws.lastSeen) > sessionTimeout {
+			delete(s.sessions, id)
+		}
+	}
+	if c, err := r.Cookie(sessionCookie); err == nil {
+		if ws, ok := s.sessions[c.Value]; ok {
+			ws.lastSeen = now
+			return ws
+		}
+	}
+	ws := &webSession{lastSeen: now}
+	s.renewSession(w, ws)
+	return ws
+}
+
+// renewSession gives the session new tokens, e.g. after logging in
+func (s *Server) renewSession(w http.ResponseWriter, ws *webSession) {
+	delete(s.sessions, ws.id)
+	ws.id = newToken()
+	ws.csrf = newToken()
+	s.sessions[ws.id] = ws
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: ws.id, Path: "/", HttpOnly: true, SameSite: http.SameSiteStrictMode})
+}
+
+// ******************************************************
+// LOGIN ATTEMPTS
+// ******************************************************
+
+// After a few wrong PINs for a profile or from an address, the next attempt has to wait,
+// twice as long after every further wrong PIN, so that a PIN can't be tried out.
+
+const (
+	freeLoginFailures = 3
+	firstLoginDelay   = 2 * time.Second
+	maxLoginDelay     = 15 * time.Minute
+)
+
+type loginFailures struct {
+	count int
+	last  time.Time
+}
+
+func (f loginFailures) wait(now time.Time) time.Duration {
+	if f.count < freeLoginFailures {
+		return 0
+	}
+	delay := maxLoginDelay
+	if shift := f.count - freeLoginFailures; shift < 20 {
+		delay = min(firstLoginDelay<<shift, maxLoginDelay)
+	}
+	return max(f.last.Add(delay).Sub(now), 0)
+}
+
+// checkPIN returns how long the login has to wait, 0 if the PIN was checked,
+// and whether it was right
+func (s *Server) checkPIN(r *http.Request, profile string, prefs core.Preferences, pin string) (time.Duration, bool) {
+	now := time.Now()
+	for key, f := range s.failures {
+		if now.Sub(f.last) > 2*maxLoginDelay {
+			delete(s.failures, key)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	keys := []string{"profile:" + profile, "addr:" + host}
+	for _, key := range keys {
+		if f, ok := s.failures[key]; ok {
+			if wait := f.wait(now); wait > 0 {
+				return wait, false
+			}
+		}
+	}
+	if prefs.CheckPIN(pin) {
+		for _, key := range keys {
+			delete(s.failures, key)
+		}
+		if prefs.OutdatedPIN() {
+			// the PIN is known now, so the old fast hash can be replaced
+			prefs.PIN = core.HashPIN(pin)
+			core.SavePreferences(core.ProfileDir(s.conf.SavDir, profile), prefs)
+		}
+		return 0, true
+	}
+	for _, key := range keys {
+		if s.failures[key] == nil {
+			s.failures[key] = &loginFailures{}
+		}
+		s.failures[key].count++
+		s.failures[key].last = now
+	}
+	return 0, false
+}
+
+// retryAfter rounds up to whole seconds, as in the Retry-After header
+func retryAfter(wait time.Duration) string {
+	return strconv.Itoa(int((wait + time.Second - 1) / time.Second))
+}
+
+func newToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// openProfile opens a profile once and shares it between all sessions logged into it
+func (s *Server) openProfile(name string) *core.Profile {
+	if p, ok := s.profiles[name]; ok {
+		return p
+	}
+	p, errorList := core.OpenProfile(s.conf.SavDir, name, s.conf.Storage)
+	for _, err := range errorList {
+		log.Print(core.T("serve.profileError", "Profile", name, "Err", err))
+	}
+	if err := p.AcquireLock(); err != nil {
+		log.Print(core.T("serve.profileError", "Profile", name, "Err", err))
+	}
+	s.profiles[name] = p
+	return p
+}